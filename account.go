@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+)
+
+func accountHandler(w http.ResponseWriter, req *http.Request) {
+	username, _ := signedIn(req)
+	renderAccount(w, accountData(username), http.StatusOK)
+}
+
+// Registers a bot for the signed in player and shows its token, which cannot be shown again.
+func createBotHandler(w http.ResponseWriter, req *http.Request) {
+	username, ok := signedIn(req)
+	if !ok {
+		renderAccount(w, view.AccountData{Error: "sign in to register a bot"}, http.StatusUnauthorized)
+		return
+	}
+	name := req.FormValue("name")
+	token, err := database.CreateBot(username, name)
+	if err != nil {
+		data := accountData(username)
+		data.Error = err.Error()
+		status := http.StatusBadRequest
+		if errors.Is(err, storage.ErrBotNameTaken) {
+			status = http.StatusConflict
+		}
+		renderAccount(w, data, status)
+		return
+	}
+	data := accountData(username)
+	data.Token = token
+	data.BotName = name
+	renderAccount(w, data, http.StatusCreated)
+}
+
+// Returns the account page of a player, with their bots if they are signed in.
+func accountData(username string) view.AccountData {
+	data := view.AccountData{Username: username}
+	if username == "" {
+		return data
+	}
+	bots, err := database.Bots(username)
+	if err != nil {
+		log.Println("Bots:", err)
+	}
+	data.Bots = bots
+	return data
+}
+
+func registerHandler(w http.ResponseWriter, req *http.Request) {
+	username, password := req.FormValue("username"), req.FormValue("password")
+	_, err := database.CreateAccount(username, password)
+	if err != nil {
+		if !errors.Is(err, storage.ErrUsernameTaken) && storage.ValidateCredentials(username, password) == nil {
+			log.Println("CreateAccount:", err)
+		}
+		renderAccount(w, view.AccountData{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+	claimGuest(req, username)
+	startSession(w, req, username)
+}
+
+func loginHandler(w http.ResponseWriter, req *http.Request) {
+	account, err := database.Authenticate(req.FormValue("username"), req.FormValue("password"))
+	if err != nil {
+		if !errors.Is(err, storage.ErrInvalidCredentials) {
+			log.Println("Authenticate:", err)
+		}
+		renderAccount(w, view.AccountData{Error: storage.ErrInvalidCredentials.Error()}, http.StatusUnauthorized)
+		return
+	}
+	claimGuest(req, account.Username)
+	startSession(w, req, account.Username)
+}
+
+func logoutHandler(w http.ResponseWriter, req *http.Request) {
+	if cookie, err := req.Cookie(SessionCookie); err == nil {
+		if err := database.DeleteSession(cookie.Value); err != nil {
+			log.Println("DeleteSession:", err)
+		}
+	}
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: "", Path: "/", MaxAge: -1})
+	http.Redirect(w, req, "/game", http.StatusSeeOther)
+}
+
+// Signs a player in by cookie and returns them to the main page.
+func startSession(w http.ResponseWriter, req *http.Request, username string) {
+	token, err := database.CreateSession(username)
+	if err != nil {
+		log.Println("CreateSession:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(storage.SessionLength.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, req, "/game", http.StatusSeeOther)
+}
+
+// Returns the username of the signed in player making a request.
+func signedIn(req *http.Request) (string, bool) {
+	cookie, err := req.Cookie(SessionCookie)
+	if err != nil {
+		return "", false
+	}
+	username, err := database.SessionUser(cookie.Value)
+	if err != nil {
+		return "", false
+	}
+	return username, true
+}
+
+// Gives everything a player did as a guest before signing in to their account: their games, finished or not,
+// with the history, leaderboard results, runs and race results recorded for them, their daily challenge
+// attempts and their campaign progress. Races and flag battles still in progress stay with the guest.
+func claimGuest(req *http.Request, username string) {
+	cookie, err := req.Cookie(PlayerCookie)
+	if err != nil || !storage.ValidGuest(cookie.Value) {
+		return
+	}
+	guest := cookie.Value
+	names, err := database.GameNames(guest)
+	if err != nil {
+		log.Println("GameNames:", err)
+		return
+	}
+	for _, name := range names {
+		claimGame(name, guest, username)
+	}
+	if err := database.ClaimGuest(guest, username); err != nil {
+		log.Println("ClaimGuest:", err)
+	}
+	if err := storage.ClaimAttempts(guest, username); err != nil {
+		log.Println("ClaimAttempts:", err)
+	}
+	claimProgress(guest, username)
+}
+
+// Reassigns the save of one of a guest's games to their account.
+func claimGame(name string, guest string, username string) {
+	unlock := lockGame(name)
+	defer unlock()
+	gameSave, err := storage.Load(name)
+	if err != nil {
+		log.Println("Load:", err)
+		return
+	}
+	gameSave.Reassign(guest, username)
+	if err := gameSave.Save(name); err != nil {
+		log.Println("GameSave#Save:", err)
+	}
+}
+
+// Adds the campaign levels a guest completed to those of their account.
+func claimProgress(guest string, username string) {
+	guestProgress, err := storage.LoadProgress(guest)
+	if err != nil || len(guestProgress.Completed) == 0 {
+		return
+	}
+	progress, err := storage.LoadProgress(username)
+	if err != nil {
+		log.Println("LoadProgress:", err)
+		return
+	}
+	for key := range guestProgress.Completed {
+		progress.Complete(key)
+	}
+	if err := progress.Save(username); err != nil {
+		log.Println("Progress#Save:", err)
+	}
+}
+
+func renderAccount(w http.ResponseWriter, accountData view.AccountData, status int) {
+	w.WriteHeader(status)
+	executeTemplate(w, "account.html", accountData)
+}
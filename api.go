@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+)
+
+func apiGameHandler(w http.ResponseWriter, req *http.Request) {
+	gameCtx := req.Context().Value(GameIDString).(string)
+	gameSave, err := storage.Load(gameCtx)
+	if err != nil {
+		writeJSONError(w, "game not found", http.StatusNotFound)
+		return
+	}
+	if !canView(w, req, gameSave) {
+		writeJSONError(w, errNoSpectators.Error(), http.StatusForbidden)
+		return
+	}
+	game := gameSave.ToGame()
+	writeJSON(w, view.ToJSON(*game, gameSave.Rating, gameCtx))
+}
+
+// Writes a value as a JSON response.
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Println("Encode:", err)
+	}
+}
+
+// Writes an error as a JSON response.
+func writeJSONError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
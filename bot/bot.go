@@ -0,0 +1,267 @@
+// Package bot plays games without a player, using a Strategy to choose every move from what is visible on
+// the board. It is used to benchmark solver changes and to check that boards meant to need no guesses do not.
+package bot
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/solver"
+)
+
+// Strategy chooses moves from the state a player can see. Deduce is tried first and Guess is only asked for a
+// tile when it proves nothing.
+type Strategy interface {
+	// Deduce returns the hidden tiles proven to be safe or mines, or nothing when it is stuck.
+	Deduce(state solver.State) []solver.Deduction
+	// Guess picks a hidden tile to reveal. Randomness must come from rng so games can be repeated.
+	Guess(state solver.State, rng *rand.Rand) game.Coordinate
+}
+
+// Strategies are the built in strategies by name.
+var Strategies = map[string]Strategy{
+	"safest": Safest{},
+	"random": Random{},
+	"blind":  Blind{},
+}
+
+// StrategyNames lists the built in strategies, strongest first.
+var StrategyNames = []string{"safest", "random", "blind"}
+
+// Safest deduces with the solver and guesses the tile least likely to be a mine, preferring corners and
+// edges among equally likely tiles as they are more likely to open an area.
+type Safest struct{}
+
+func (Safest) Deduce(state solver.State) []solver.Deduction {
+	deductions, _ := solver.Deduce(state)
+	return deductions
+}
+
+func (Safest) Guess(state solver.State, rng *rand.Rand) game.Coordinate {
+	probability := solver.Analyze(state).Probability
+	hidden := state.HiddenTiles()
+	width, height := len(state.Field[0]), len(state.Field)
+	borders := func(coord game.Coordinate) int {
+		count := 0
+		if coord.X == 0 || coord.X == width-1 {
+			count++
+		}
+		if coord.Y == 0 || coord.Y == height-1 {
+			count++
+		}
+		return count
+	}
+	best := []game.Coordinate{}
+	for _, coord := range hidden {
+		if len(best) == 0 {
+			best = append(best, coord)
+			continue
+		}
+		current, leader := probability[coord], probability[best[0]]
+		switch {
+		case current < leader || (current == leader && borders(coord) > borders(best[0])):
+			best = []game.Coordinate{coord}
+		case current == leader && borders(coord) == borders(best[0]):
+			best = append(best, coord)
+		}
+	}
+	return best[rng.Intn(len(best))]
+}
+
+// Random deduces with the solver and guesses any hidden tile.
+type Random struct{}
+
+func (Random) Deduce(state solver.State) []solver.Deduction {
+	deductions, _ := solver.Deduce(state)
+	return deductions
+}
+
+func (Random) Guess(state solver.State, rng *rand.Rand) game.Coordinate {
+	hidden := state.HiddenTiles()
+	return hidden[rng.Intn(len(hidden))]
+}
+
+// Blind never deduces anything and reveals hidden tiles at random, as a baseline.
+type Blind struct{}
+
+func (Blind) Deduce(state solver.State) []solver.Deduction {
+	return nil
+}
+
+func (Blind) Guess(state solver.State, rng *rand.Rand) game.Coordinate {
+	return Random{}.Guess(state, rng)
+}
+
+// Result is the outcome of one game played by a bot.
+type Result struct {
+	Won bool
+	// Guesses counts the moves made when the strategy could prove nothing. The first click is not counted, as
+	// nothing can be known before it.
+	Guesses  int
+	Moves    int
+	Cleared  float64
+	Duration time.Duration
+}
+
+// Plays a board to the end with a strategy. The game starts by revealing start if it is given, otherwise with
+// the strategy's guess. Proven mines are flagged so later deductions can use them.
+func Play(board generation.Board, strategy Strategy, rng *rand.Rand, start *game.Coordinate) Result {
+	began := time.Now()
+	current := game.NewGame(board)
+	result := Result{}
+	if start != nil {
+		current.Play(game.Move{Coordinate: *start, Action: game.Reveal})
+	}
+	for !current.Over() {
+		state := solver.FromGame(*current)
+		for y, row := range current.Flags {
+			for x, flagged := range row {
+				if flagged {
+					state.Flag(game.Coordinate{X: x, Y: y})
+				}
+			}
+		}
+		progressed := false
+		for _, deduction := range strategy.Deduce(state) {
+			if deduction.Mine {
+				progressed = current.Play(game.Move{Coordinate: deduction.Coordinate, Action: game.Flag}) || progressed
+			} else {
+				progressed = current.Play(game.Move{Coordinate: deduction.Coordinate, Action: game.Reveal}) || progressed
+			}
+		}
+		if !progressed {
+			if len(current.Moves) > 0 {
+				result.Guesses++
+			}
+			if !current.Play(game.Move{Coordinate: strategy.Guess(state, rng), Action: game.Reveal}) {
+				// A guess that changes nothing would be repeated forever, so the game is given up.
+				break
+			}
+		}
+	}
+	result.Won = current.Won()
+	result.Moves = len(current.Moves)
+	result.Cleared = current.Cleared()
+	result.Duration = time.Since(began)
+	return result
+}
+
+// Summary aggregates the results of many games of one difficulty.
+type Summary struct {
+	Difficulty string
+	Games      int
+	Wins       int
+	Guesses    int
+	// NoGuessWins counts the games won without guessing.
+	NoGuessWins int
+	Duration    time.Duration
+	// Durations holds the time taken by each game, sorted, for percentiles.
+	Durations []time.Duration
+}
+
+// Adds a result to a summary.
+func (summary *Summary) Add(result Result) {
+	summary.Games++
+	summary.Guesses += result.Guesses
+	summary.Duration += result.Duration
+	if result.Won {
+		summary.Wins++
+		if result.Guesses == 0 {
+			summary.NoGuessWins++
+		}
+	}
+	index, _ := slices.BinarySearch(summary.Durations, result.Duration)
+	summary.Durations = slices.Insert(summary.Durations, index, result.Duration)
+}
+
+// Returns the fraction of games won.
+func (summary Summary) WinRate() float64 {
+	if summary.Games == 0 {
+		return 0
+	}
+	return float64(summary.Wins) / float64(summary.Games)
+}
+
+// Returns the mean number of guesses per game.
+func (summary Summary) AverageGuesses() float64 {
+	if summary.Games == 0 {
+		return 0
+	}
+	return float64(summary.Guesses) / float64(summary.Games)
+}
+
+// Returns the mean time taken per game.
+func (summary Summary) TimePerGame() time.Duration {
+	if summary.Games == 0 {
+		return 0
+	}
+	return summary.Duration / time.Duration(summary.Games)
+}
+
+// Returns the time within which a fraction of the games finished, such as 0.99 for the 99th percentile.
+func (summary Summary) Percentile(fraction float64) time.Duration {
+	if len(summary.Durations) == 0 {
+		return 0
+	}
+	index := min(int(fraction*float64(len(summary.Durations))), len(summary.Durations)-1)
+	return summary.Durations[index]
+}
+
+func (summary Summary) String() string {
+	return fmt.Sprintf("%-12s games:%d won:%.1f%% no-guess wins:%d guesses/game:%.2f time/game:%s p99:%s",
+		summary.Difficulty, summary.Games, summary.WinRate()*100, summary.NoGuessWins, summary.AverageGuesses(),
+		summary.TimePerGame(), summary.Percentile(0.99))
+}
+
+// Benchmark plays count games of a preset with a strategy. Game i is played on the board generated from seed+i
+// with its guesses drawn from the same seed, so runs with the same arguments make the same moves.
+// Games are spread over workers, which does not change their results.
+func Benchmark(difficulty string, strategy Strategy, seed int64, count int, workers int) (Summary, error) {
+	preset, ok := generation.Presets[difficulty]
+	if !ok {
+		return Summary{}, fmt.Errorf("unknown difficulty %s", difficulty)
+	}
+	workers = max(workers, 1)
+	results := make([]Result, count)
+	errs := make(chan error, workers)
+	indexes := make(chan int)
+	for range workers {
+		go func() {
+			var failed error
+			for index := range indexes {
+				if failed != nil {
+					continue
+				}
+				board, err := generation.NewBoard(preset.Mines, preset.Width, preset.Height, seed+int64(index))
+				if err != nil {
+					failed = err
+					continue
+				}
+				results[index] = Play(*board, strategy, rand.New(rand.NewSource(seed+int64(index))), nil)
+			}
+			errs <- failed
+		}()
+	}
+	for index := range count {
+		indexes <- index
+	}
+	close(indexes)
+	var failed error
+	for range workers {
+		if err := <-errs; err != nil && failed == nil {
+			failed = err
+		}
+	}
+	if failed != nil {
+		return Summary{}, failed
+	}
+	summary := Summary{Difficulty: difficulty}
+	for _, result := range results {
+		summary.Add(result)
+	}
+	return summary, nil
+}
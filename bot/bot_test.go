@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"log"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+func TestPlayNoGuessBoard(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"*....",
+		".....",
+		".....",
+		"....*",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	start := game.Coordinate{X: 2, Y: 2}
+	result := Play(*board, Safest{}, rand.New(rand.NewSource(1)), &start)
+	if !result.Won || result.Guesses != 0 || result.Cleared != 1 {
+		log.Printf("Expected the board to be cleared without guessing. Actual: %+v", result)
+		test.Fail()
+	}
+}
+
+func TestBenchmarkIsDeterministic(test *testing.T) {
+	first, err := Benchmark("beginner", Safest{}, 7, 40, 4)
+	if err != nil {
+		log.Printf("Error running benchmark: %s", err)
+		test.FailNow()
+	}
+	second, _ := Benchmark("beginner", Safest{}, 7, 40, 1)
+	if first.Games != 40 || first.Wins != second.Wins || first.Guesses != second.Guesses || first.NoGuessWins != second.NoGuessWins {
+		log.Printf("Expected repeated runs to match. Actual: %+v %+v", first, second)
+		test.Fail()
+	}
+	if _, err := Benchmark("impossible", Safest{}, 7, 1, 1); err == nil {
+		log.Printf("Expected an unknown difficulty to be rejected.")
+		test.Fail()
+	}
+}
+
+func TestSummary(test *testing.T) {
+	summary := Summary{}
+	summary.Add(Result{Won: true, Duration: 3 * time.Millisecond})
+	summary.Add(Result{Won: false, Guesses: 2, Duration: time.Millisecond})
+	summary.Add(Result{Won: true, Guesses: 1, Duration: 2 * time.Millisecond})
+	if summary.WinRate() != 2.0/3 || summary.AverageGuesses() != 1 || summary.NoGuessWins != 1 {
+		log.Printf("Summary totals were not as expected. Actual: %+v", summary)
+		test.Fail()
+	}
+	if summary.TimePerGame() != 2*time.Millisecond || summary.Percentile(0) != time.Millisecond || summary.Percentile(1) != 3*time.Millisecond {
+		log.Printf("Summary times were not as expected. Actual: %+v", summary.Durations)
+		test.Fail()
+	}
+}
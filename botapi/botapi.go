@@ -0,0 +1,391 @@
+// Package botapi is the HTTP API for programmatic players. Bots authenticate with a token registered by an
+// account, create games on preset boards, read what is visible of them, submit moves in batches and are ranked
+// on leaderboards kept apart from people's. Hidden tiles are never sent.
+package botapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+	"github.com/go-chi/chi/v5"
+)
+
+// MaxBatch is the most moves that can be submitted in one request.
+const MaxBatch = 100
+
+// budgetPerTile is the number of moves a bot may make per tile of the board before the game refuses more.
+const budgetPerTile = 2
+
+type contextName string
+
+const botContext contextName = "bot"
+
+// GameState is what a bot can see of one of its games.
+type GameState struct {
+	view.GameJSON
+	Bot        string `json:"bot"`
+	Difficulty string `json:"difficulty"`
+	MovesUsed  int    `json:"movesUsed"`
+	MoveBudget int    `json:"moveBudget"`
+}
+
+// Move is a click submitted by a bot. An empty action reveals.
+type Move struct {
+	X      int         `json:"x"`
+	Y      int         `json:"y"`
+	Action game.Action `json:"action,omitempty"`
+}
+
+// MovesRequest is a batch of moves, applied in order until the game ends.
+type MovesRequest struct {
+	Moves []Move `json:"moves"`
+}
+
+// MovesResponse reports how many moves of a batch were applied and the game after them.
+type MovesResponse struct {
+	Applied int       `json:"applied"`
+	State   GameState `json:"state"`
+}
+
+// Result is the outcome of a finished game.
+type Result struct {
+	ID         string     `json:"id"`
+	Won        bool       `json:"won"`
+	DurationMs int64      `json:"durationMs"`
+	Stats      game.Stats `json:"stats"`
+	// Ranked is true if the game was accepted onto its bot leaderboard.
+	Ranked bool `json:"ranked"`
+}
+
+// LeaderboardJSON is a bot leaderboard.
+type LeaderboardJSON struct {
+	Difficulty string           `json:"difficulty"`
+	Ranking    storage.Ranking  `json:"ranking"`
+	Top        []storage.Result `json:"top"`
+}
+
+// Server serves the bot API for the games saved in storage.
+type Server struct {
+	db      *storage.DB
+	limiter *limiter
+	now     func() time.Time
+	// locks holds a mutex per game name while a request holds or waits for it.
+	locks      map[string]*gameLock
+	locksMutex sync.Mutex
+	// Lock, when set, is used instead of the server's own locks to serialise changes to a game.
+	Lock func(name string) func()
+	// Played, when set, is called with each game after a batch of moves changes it.
+	Played func(name string, current *game.Game)
+}
+
+func NewServer(db *storage.DB, limit Limit) *Server {
+	return &Server{db: db, limiter: newLimiter(limit), now: time.Now, locks: map[string]*gameLock{}}
+}
+
+// Returns the API's routes, to be mounted under a prefix such as /api/bot.
+func (server *Server) Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/leaderboard/{difficulty}", server.leaderboardHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(server.authenticate)
+		r.Post("/games", server.createHandler)
+		r.Get("/games/{id}", server.stateHandler)
+		r.Post("/games/{id}/moves", server.movesHandler)
+		r.Get("/games/{id}/result", server.resultHandler)
+	})
+	return r
+}
+
+// authenticate finds the bot of a request's bearer token and enforces its rate limit.
+func (server *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			writeError(w, "a bot token is required", http.StatusUnauthorized)
+			return
+		}
+		bot, err := server.db.BotForToken(token)
+		if errors.Is(err, storage.ErrNoBot) {
+			writeError(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Println("BotForToken:", err)
+			writeError(w, "bot could not be found", http.StatusInternalServerError)
+			return
+		}
+		if ok, wait := server.limiter.allow(bot.Name, server.now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), botContext, bot)))
+	})
+}
+
+// Creates a game on a random board of a preset difficulty.
+func (server *Server) createHandler(w http.ResponseWriter, req *http.Request) {
+	bot := req.Context().Value(botContext).(storage.Bot)
+	var body struct {
+		Difficulty string `json:"difficulty"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, "request body must be JSON", http.StatusBadRequest)
+		return
+	}
+	preset, ok := generation.Presets[body.Difficulty]
+	if !ok {
+		writeError(w, fmt.Sprintf("difficulty must be one of %s", strings.Join(generation.PresetNames, ", ")), http.StatusBadRequest)
+		return
+	}
+	seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	board, err := generation.NewBoard(preset.Mines, preset.Width, preset.Height, seed.Int64())
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	name, err := newName()
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	current := game.NewGame(*board)
+	gameSave := storage.FromGame(*current)
+	gameSave.Player = bot.Player()
+	gameSave.Bot = bot.Name
+	gameSave.Difficulty = body.Difficulty
+	gameSave.Started = server.now().UnixMilli()
+	server.save(gameSave, name)
+	log.Printf("Bot %s created game %s", bot.Name, name)
+	w.Header().Set("Location", req.URL.Path+"/"+name)
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, toState(gameSave, current, name))
+}
+
+func (server *Server) stateHandler(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "id")
+	gameSave, ok := server.load(w, req, name)
+	if !ok {
+		return
+	}
+	writeJSON(w, toState(gameSave, gameSave.ToGame(), name))
+}
+
+// Applies a batch of moves. The whole batch is rejected if any move is invalid or it would exceed the game's
+// move budget, so a batch is either applied in order or not at all.
+func (server *Server) movesHandler(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "id")
+	var batch MovesRequest
+	if err := json.NewDecoder(req.Body).Decode(&batch); err != nil {
+		writeError(w, "request body must be JSON", http.StatusBadRequest)
+		return
+	}
+	if len(batch.Moves) == 0 || len(batch.Moves) > MaxBatch {
+		writeError(w, fmt.Sprintf("a batch must have between 1 and %d moves", MaxBatch), http.StatusBadRequest)
+		return
+	}
+
+	defer server.lock(name)()
+	gameSave, ok := server.load(w, req, name)
+	if !ok {
+		return
+	}
+	current := gameSave.ToGame()
+	for index, move := range batch.Moves {
+		if !current.Board.IsInRange(move.Y, move.X) {
+			writeError(w, fmt.Sprintf("move %d is not on the board", index), http.StatusBadRequest)
+			return
+		}
+		switch move.Action {
+		case "", game.Reveal, game.Flag, game.Chord:
+		default:
+			writeError(w, fmt.Sprintf("move %d has an unknown action %q", index, move.Action), http.StatusBadRequest)
+			return
+		}
+	}
+	if current.Over() {
+		writeError(w, "game is over", http.StatusConflict)
+		return
+	}
+	if len(current.Moves)+len(batch.Moves) > moveBudget(gameSave) {
+		writeError(w, "batch would exceed the game's move budget", http.StatusConflict)
+		return
+	}
+
+	elapsed := time.Duration(server.now().UnixMilli()-gameSave.Started) * time.Millisecond
+	applied := 0
+	for _, move := range batch.Moves {
+		if current.Over() {
+			break
+		}
+		current.Play(game.Move{Coordinate: game.Coordinate{X: move.X, Y: move.Y}, Action: move.Action, Elapsed: elapsed})
+		applied++
+	}
+	gameSave.Record(*current)
+	if current.Over() {
+		gameSave.Finished = server.now().UnixMilli()
+		gameSave.Won = current.Won()
+		if gameSave.Won {
+			if err := server.db.SubmitResult(name, gameSave); err != nil {
+				log.Printf("SubmitResult: rejected %s: %s", name, err)
+			}
+		}
+	}
+	server.save(gameSave, name)
+	if server.Played != nil {
+		server.Played(name, current)
+	}
+	writeJSON(w, MovesResponse{Applied: applied, State: toState(gameSave, current, name)})
+}
+
+func (server *Server) resultHandler(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "id")
+	gameSave, ok := server.load(w, req, name)
+	if !ok {
+		return
+	}
+	if gameSave.Finished == 0 {
+		writeError(w, "game has not finished", http.StatusConflict)
+		return
+	}
+	writeJSON(w, Result{
+		ID:         name,
+		Won:        gameSave.Won,
+		DurationMs: gameSave.Duration().Milliseconds(),
+		Stats:      gameSave.ToGame().Stats(),
+		Ranked:     gameSave.Won && storage.ValidateResult(gameSave) == nil,
+	})
+}
+
+func (server *Server) leaderboardHandler(w http.ResponseWriter, req *http.Request) {
+	difficulty := chi.URLParam(req, "difficulty")
+	if _, ok := generation.Presets[difficulty]; !ok {
+		writeError(w, "leaderboard not found", http.StatusNotFound)
+		return
+	}
+	ranking := storage.ParseRanking(req.FormValue("by"))
+	limit, err := strconv.Atoi(req.FormValue("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	top, err := server.db.TopResults(storage.BotLeaderboard(difficulty), ranking, limit)
+	if err != nil {
+		log.Println("TopResults:", err)
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, LeaderboardJSON{Difficulty: difficulty, Ranking: ranking, Top: top})
+}
+
+// Loads a game belonging to the requesting bot, writing an error response and returning false if it cannot.
+func (server *Server) load(w http.ResponseWriter, req *http.Request, name string) (*storage.GameSave, bool) {
+	bot := req.Context().Value(botContext).(storage.Bot)
+	gameSave, err := storage.Load(name)
+	if err != nil {
+		writeError(w, "game not found", http.StatusNotFound)
+		return nil, false
+	}
+	if gameSave.Bot != bot.Name {
+		writeError(w, "game belongs to another player", http.StatusForbidden)
+		return nil, false
+	}
+	return gameSave, true
+}
+
+func (server *Server) save(gameSave *storage.GameSave, name string) {
+	if err := gameSave.Save(name); err != nil {
+		log.Println("GameSave#Save:", err)
+		return
+	}
+	if err := server.db.IndexGame(name, gameSave); err != nil {
+		log.Println("IndexGame:", err)
+	}
+}
+
+func (server *Server) lock(name string) func() {
+	if server.Lock != nil {
+		return server.Lock(name)
+	}
+	server.locksMutex.Lock()
+	lock, ok := server.locks[name]
+	if !ok {
+		lock = &gameLock{}
+		server.locks[name] = lock
+	}
+	lock.users++
+	server.locksMutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		server.locksMutex.Lock()
+		defer server.locksMutex.Unlock()
+		lock.users--
+		if lock.users == 0 {
+			delete(server.locks, name)
+		}
+	}
+}
+
+// gameLock is the mutex of a game and the number of requests holding or waiting for it.
+type gameLock struct {
+	sync.Mutex
+	users int
+}
+
+// Returns the number of moves a game allows.
+func moveBudget(gameSave *storage.GameSave) int {
+	return budgetPerTile * gameSave.Width * gameSave.Height
+}
+
+func toState(gameSave *storage.GameSave, current *game.Game, name string) GameState {
+	return GameState{
+		GameJSON:   view.ToJSON(*current, nil, name),
+		Bot:        gameSave.Bot,
+		Difficulty: gameSave.Difficulty,
+		MovesUsed:  len(current.Moves),
+		MoveBudget: moveBudget(gameSave),
+	}
+}
+
+// Returns a random game name in the same form as the names of games created by people.
+func newName() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Println("Encode:", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
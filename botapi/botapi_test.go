@@ -0,0 +1,186 @@
+package botapi
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+// Starts the API on a fresh database with one bot and returns a client for it.
+func startTestServer(test *testing.T, limit Limit) (*storage.DB, *httptest.Server, *Client) {
+	storage.PathCrumb = test.TempDir()
+	db, err := storage.Open(filepath.Join(test.TempDir(), "test.db"))
+	if err != nil {
+		log.Printf("Error opening database: %s", err)
+		test.FailNow()
+	}
+	test.Cleanup(func() { db.Close() })
+	if _, err := db.CreateAccount("sweeper", "correct horse"); err != nil {
+		log.Printf("Error creating account: %s", err)
+		test.FailNow()
+	}
+	token, err := db.CreateBot("sweeper", "flagbot")
+	if err != nil {
+		log.Printf("Error creating bot: %s", err)
+		test.FailNow()
+	}
+	server := httptest.NewServer(NewServer(db, limit).Routes())
+	test.Cleanup(server.Close)
+	return db, server, &Client{BaseURL: server.URL, Token: token}
+}
+
+func statusOf(err error) int {
+	apiErr := &APIError{}
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return 0
+}
+
+func TestBotPlaysRankedGame(test *testing.T) {
+	db, _, client := startTestServer(test, DefaultLimit)
+	state, err := client.Create("beginner")
+	if err != nil {
+		log.Printf("Error creating game: %s", err)
+		test.FailNow()
+	}
+	for _, row := range state.Field {
+		for _, tile := range row {
+			if tile != -1 {
+				log.Printf("Expected a new game to show only hidden tiles. Actual: %v", state.Field)
+				test.FailNow()
+			}
+		}
+	}
+	if state.Stats.ThreeBV != 0 {
+		log.Printf("Expected no statistics of the hidden board before the game is over. Actual: %+v", state.Stats)
+		test.Fail()
+	}
+
+	gameSave, err := storage.Load(state.Name)
+	if err != nil {
+		log.Printf("Error loading game: %s", err)
+		test.FailNow()
+	}
+	board := gameSave.ToGame().Board
+	moves := []Move{}
+	for y, row := range board.Field {
+		for x, tile := range row {
+			if tile != -9 {
+				moves = append(moves, Move{X: x, Y: y})
+			}
+		}
+	}
+	response, err := client.Move(state.Name, moves...)
+	if err != nil || !response.State.Won || response.Applied > len(moves) || response.State.MovesUsed != response.Applied {
+		log.Printf("Expected the batch to win the game. Actual: %+v %v", response, err)
+		test.FailNow()
+	}
+	if response.State.Stats.ThreeBV == 0 {
+		log.Printf("Expected statistics once the game is over. Actual: %+v", response.State.Stats)
+		test.Fail()
+	}
+
+	result, err := client.Result(state.Name)
+	if err != nil || !result.Won || !result.Ranked {
+		log.Printf("Expected a ranked win. Actual: %+v %v", result, err)
+		test.Fail()
+	}
+	leaderboard, err := client.Leaderboard("beginner", 10)
+	if err != nil || len(leaderboard.Top) != 1 || leaderboard.Top[0].Player != "bot:flagbot" {
+		log.Printf("Expected the win on the bot leaderboard. Actual: %+v %v", leaderboard, err)
+		test.Fail()
+	}
+	if people, _ := db.TopResults("beginner", storage.ByTime, 10); len(people) != 0 {
+		log.Printf("Expected no bot results on the players' leaderboard. Actual: %+v", people)
+		test.Fail()
+	}
+	if _, err := client.Move(state.Name, Move{}); statusOf(err) != http.StatusConflict {
+		log.Printf("Expected moves on a finished game to conflict. Actual: %v", err)
+		test.Fail()
+	}
+}
+
+func TestBotRequestsAreChecked(test *testing.T) {
+	db, server, client := startTestServer(test, DefaultLimit)
+	state, err := client.Create("beginner")
+	if err != nil {
+		log.Printf("Error creating game: %s", err)
+		test.FailNow()
+	}
+	if _, err := client.Result(state.Name); statusOf(err) != http.StatusConflict {
+		log.Printf("Expected the result of an unfinished game to conflict. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := client.Create("impossible"); statusOf(err) != http.StatusBadRequest {
+		log.Printf("Expected an unknown difficulty to be rejected. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := client.Move(state.Name, make([]Move, MaxBatch+1)...); statusOf(err) != http.StatusBadRequest {
+		log.Printf("Expected an oversized batch to be rejected. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := client.Move(state.Name, Move{X: 0, Y: 0}, Move{X: 99, Y: 0}); statusOf(err) != http.StatusBadRequest {
+		log.Printf("Expected a batch with a move off the board to be rejected. Actual: %v", err)
+		test.Fail()
+	}
+	if current, _ := client.State(state.Name); current.MovesUsed != 0 {
+		log.Printf("Expected a rejected batch to apply no moves. Actual: %+v", current)
+		test.Fail()
+	}
+
+	anonymous := &Client{BaseURL: server.URL}
+	if _, err := anonymous.State(state.Name); statusOf(err) != http.StatusUnauthorized {
+		log.Printf("Expected a request without a token to be unauthorized. Actual: %v", err)
+		test.Fail()
+	}
+	token, err := db.CreateBot("sweeper", "otherbot")
+	if err != nil {
+		log.Printf("Error creating bot: %s", err)
+		test.FailNow()
+	}
+	other := &Client{BaseURL: server.URL, Token: token}
+	if _, err := other.State(state.Name); statusOf(err) != http.StatusForbidden {
+		log.Printf("Expected another bot's game to be forbidden. Actual: %v", err)
+		test.Fail()
+	}
+}
+
+func TestBotRateLimit(test *testing.T) {
+	_, _, client := startTestServer(test, Limit{Rate: 0.001, Burst: 2})
+	state, err := client.Create("beginner")
+	if err != nil {
+		log.Printf("Error creating game: %s", err)
+		test.FailNow()
+	}
+	if _, err := client.State(state.Name); err != nil {
+		log.Printf("Expected a request within the burst to succeed. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := client.State(state.Name); statusOf(err) != http.StatusTooManyRequests {
+		log.Printf("Expected a request beyond the burst to be limited. Actual: %v", err)
+		test.Fail()
+	}
+}
+
+func TestLimiterDropsFullBuckets(test *testing.T) {
+	limiter := newLimiter(Limit{Rate: 10, Burst: 20})
+	start := time.UnixMilli(1000000)
+	limiter.allow("bot:idle", start)
+	limiter.allow("bot:busy", start.Add(time.Second))
+	if len(limiter.buckets) != 2 {
+		log.Printf("Expected a bucket per bot. Actual: %d", len(limiter.buckets))
+		test.FailNow()
+	}
+	limiter.allow("bot:busy", start.Add(2*time.Second+time.Millisecond))
+	if _, ok := limiter.buckets["bot:idle"]; ok || len(limiter.buckets) != 1 {
+		log.Printf("Expected the bucket that filled up again to be dropped. Actual: %v", limiter.buckets)
+		test.Fail()
+	}
+}
@@ -0,0 +1,103 @@
+package botapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Client calls the bot API on behalf of one bot.
+type Client struct {
+	// BaseURL is where the API is mounted, such as http://localhost:8080/api/bot.
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+// APIError is an error response from the API.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (err *APIError) Error() string {
+	return fmt.Sprintf("bot api: %d %s", err.Status, err.Message)
+}
+
+// Creates a game of a preset difficulty.
+func (client *Client) Create(difficulty string) (GameState, error) {
+	state := GameState{}
+	err := client.do(http.MethodPost, "/games", map[string]string{"difficulty": difficulty}, &state)
+	return state, err
+}
+
+// Returns what is visible of a game.
+func (client *Client) State(name string) (GameState, error) {
+	state := GameState{}
+	err := client.do(http.MethodGet, "/games/"+url.PathEscape(name), nil, &state)
+	return state, err
+}
+
+// Submits a batch of moves to a game.
+func (client *Client) Move(name string, moves ...Move) (MovesResponse, error) {
+	response := MovesResponse{}
+	err := client.do(http.MethodPost, "/games/"+url.PathEscape(name)+"/moves", MovesRequest{Moves: moves}, &response)
+	return response, err
+}
+
+// Returns the result of a finished game.
+func (client *Client) Result(name string) (Result, error) {
+	result := Result{}
+	err := client.do(http.MethodGet, "/games/"+url.PathEscape(name)+"/result", nil, &result)
+	return result, err
+}
+
+// Returns the top of a bot leaderboard.
+func (client *Client) Leaderboard(difficulty string, limit int) (LeaderboardJSON, error) {
+	leaderboard := LeaderboardJSON{}
+	path := "/leaderboard/" + url.PathEscape(difficulty) + "?limit=" + strconv.Itoa(limit)
+	err := client.do(http.MethodGet, path, nil, &leaderboard)
+	return leaderboard, err
+}
+
+func (client *Client) do(method string, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, client.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.Token)
+	}
+	httpClient := client.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		failure := struct {
+			Error string `json:"error"`
+		}{}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return &APIError{Status: resp.StatusCode, Message: failure.Error}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,75 @@
+package botapi
+
+import (
+	"sync"
+	"time"
+)
+
+// Limit is the rate a bot may make requests at: Rate requests a second on average, with bursts of up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultLimit allows a bot ten requests a second.
+var DefaultLimit = Limit{Rate: 10, Burst: 20}
+
+// limiter keeps a token bucket per key. Buckets that have filled up again are dropped, since a new bucket
+// starts full.
+type limiter struct {
+	limit   Limit
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+	// pruned is when full buckets were last dropped.
+	pruned time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit Limit) *limiter {
+	return &limiter{limit: limit, buckets: map[string]*bucket{}}
+}
+
+// Takes a token from a key's bucket. Returns true if one was available, otherwise false with the time until
+// the next one will be.
+func (limiter *limiter) allow(key string, now time.Time) (bool, time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.prune(now)
+	current, ok := limiter.buckets[key]
+	if !ok {
+		current = &bucket{tokens: float64(limiter.limit.Burst), last: now}
+		limiter.buckets[key] = current
+	}
+	elapsed := now.Sub(current.last).Seconds()
+	current.tokens = min(float64(limiter.limit.Burst), current.tokens+elapsed*limiter.limit.Rate)
+	current.last = now
+	if current.tokens >= 1 {
+		current.tokens--
+		return true, 0
+	}
+	wait := (1 - current.tokens) / limiter.limit.Rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// Returns how long an empty bucket takes to fill up.
+func (limiter *limiter) refill() time.Duration {
+	return time.Duration(float64(limiter.limit.Burst) / limiter.limit.Rate * float64(time.Second))
+}
+
+// Drops the buckets that have filled up since they were last used, at most once per refill. The limiter must
+// be locked.
+func (limiter *limiter) prune(now time.Time) {
+	if now.Sub(limiter.pruned) < limiter.refill() {
+		return
+	}
+	limiter.pruned = now
+	for key, current := range limiter.buckets {
+		if now.Sub(current.last) >= limiter.refill() {
+			delete(limiter.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/deadly990/gominesweeper/campaign"
+	"github.com/deadly990/gominesweeper/solver"
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+	"github.com/go-chi/chi/v5"
+)
+
+func campaignHandler(w http.ResponseWriter, req *http.Request) {
+	progress, err := storage.LoadProgress(playerName(w, req))
+	if err != nil {
+		log.Println("LoadProgress:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	campaignData := view.FromCampaign(campaign.Packs(), progress.Completed)
+	executeTemplate(w, "campaign.html", campaignData)
+}
+
+func levelHandler(w http.ResponseWriter, req *http.Request) {
+	packID := req.Context().Value(PackIDString).(string)
+	levelID := req.Context().Value(LevelIDString).(string)
+
+	pack, level, err := campaign.Find(packID, levelID)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	player := playerName(w, req)
+	progress, err := storage.LoadProgress(player)
+	if err != nil {
+		log.Println("LoadProgress:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	if !pack.LevelUnlocked(level.ID, progress.Completed) {
+		http.Error(w, "this level has not been unlocked yet", 403)
+		return
+	}
+
+	game, err := level.NewGame()
+	if err != nil {
+		log.Println("NewGame:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	gameName := generateName(rand.Int63())
+	gameSave := storage.FromGame(*game)
+	gameSave.Layout = level.Layout
+	gameSave.Level = campaign.Key(pack.ID, level.ID)
+	gameSave.Player = player
+	gameSave.Difficulty = "campaign"
+	gameSave.Started = time.Now().UnixMilli()
+	rating := solver.Rate(game.Board, game.Moves[0].Coordinate)
+	gameSave.Rating = &rating
+	renderGame(w, req, gameSave, game, gameName)
+	saveGame(gameSave, gameName)
+}
+
+// Records a campaign level as completed for a player.
+func completeLevel(player string, key string) {
+	progress, err := storage.LoadProgress(player)
+	if err != nil {
+		log.Println("LoadProgress:", err)
+		return
+	}
+	progress.Complete(key)
+	if err := progress.Save(player); err != nil {
+		log.Println("Progress#Save:", err)
+	}
+}
+
+func LevelCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), PackIDString, chi.URLParam(req, string(PackIDString)))
+		ctx = context.WithValue(ctx, LevelIDString, chi.URLParam(req, string(LevelIDString)))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,171 @@
+package campaign
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+//go:embed packs/*.json
+var packFiles embed.FS
+
+// Start is the tile revealed for the player when a level begins.
+type Start struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Level is a pre-determined board along with notes teaching the logic needed to solve it.
+type Level struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	Notes  []string `json:"notes"`
+	Layout []string `json:"layout"`
+	Start  Start    `json:"start"`
+}
+
+// Pack is an ordered group of levels. A pack is locked until the pack it requires is completed.
+type Pack struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Requires    string  `json:"requires"`
+	Levels      []Level `json:"levels"`
+}
+
+var packs = mustLoadPacks()
+
+func mustLoadPacks() []Pack {
+	loaded, err := loadPacks(packFiles)
+	if err != nil {
+		panic(fmt.Sprintf("campaign: %s", err))
+	}
+	return loaded
+}
+
+// Reads every pack in a file system, ordered by file name.
+func loadPacks(files fs.FS) ([]Pack, error) {
+	names, err := fs.Glob(files, "packs/*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	loaded := []Pack{}
+	for _, name := range names {
+		buffer, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+		pack := Pack{}
+		if err := json.Unmarshal(buffer, &pack); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		loaded = append(loaded, pack)
+	}
+	return loaded, nil
+}
+
+// Returns every campaign pack in play order.
+func Packs() []Pack {
+	return packs
+}
+
+// Returns the key used to record the completion of a level.
+func Key(packID string, levelID string) string {
+	return packID + "/" + levelID
+}
+
+// Returns the pack and level identified by their IDs.
+func Find(packID string, levelID string) (Pack, Level, error) {
+	for _, pack := range packs {
+		if pack.ID != packID {
+			continue
+		}
+		for _, level := range pack.Levels {
+			if level.ID == levelID {
+				return pack, level, nil
+			}
+		}
+		return pack, Level{}, fmt.Errorf("level %q does not exist in pack %q", levelID, packID)
+	}
+	return Pack{}, Level{}, fmt.Errorf("pack %q does not exist", packID)
+}
+
+// Returns the pack and level recorded under a key produced by Key.
+func FindKey(key string) (Pack, Level, error) {
+	for _, pack := range packs {
+		for _, level := range pack.Levels {
+			if Key(pack.ID, level.ID) == key {
+				return pack, level, nil
+			}
+		}
+	}
+	return Pack{}, Level{}, fmt.Errorf("campaign level %q does not exist", key)
+}
+
+// Returns the level following this one in its pack, or false if it is the last.
+func (pack Pack) Next(levelID string) (Level, bool) {
+	for index, level := range pack.Levels {
+		if level.ID == levelID && index+1 < len(pack.Levels) {
+			return pack.Levels[index+1], true
+		}
+	}
+	return Level{}, false
+}
+
+// Returns true if every level in the pack has been completed.
+func (pack Pack) Completed(completed map[string]bool) bool {
+	for _, level := range pack.Levels {
+		if !completed[Key(pack.ID, level.ID)] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if the pack has no requirement or the pack it requires has been completed.
+func (pack Pack) Unlocked(completed map[string]bool) bool {
+	if pack.Requires == "" {
+		return true
+	}
+	for _, required := range packs {
+		if required.ID == pack.Requires {
+			return required.Completed(completed)
+		}
+	}
+	return false
+}
+
+// Returns true if the pack is unlocked and the level is either the first of its pack or follows a completed level.
+func (pack Pack) LevelUnlocked(levelID string, completed map[string]bool) bool {
+	if !pack.Unlocked(completed) {
+		return false
+	}
+	for index, level := range pack.Levels {
+		if level.ID == levelID {
+			return index == 0 || completed[Key(pack.ID, pack.Levels[index-1].ID)]
+		}
+	}
+	return false
+}
+
+// Returns the Board described by the level's layout.
+func (level Level) Board() (*generation.Board, error) {
+	return generation.FromLayout(level.Layout)
+}
+
+// Returns a new Game of the level with its start tile already revealed.
+func (level Level) NewGame() (*game.Game, error) {
+	board, err := level.Board()
+	if err != nil {
+		return nil, err
+	}
+	newGame := game.NewGame(*board)
+	newGame.Move(game.Coordinate{X: level.Start.X, Y: level.Start.Y}, newGame.Clear)
+	return newGame, nil
+}
@@ -0,0 +1,82 @@
+package campaign
+
+import (
+	"log"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/solver"
+)
+
+func TestLevelsAreValid(test *testing.T) {
+	for _, pack := range Packs() {
+		for _, level := range pack.Levels {
+			board, err := level.Board()
+			if err != nil {
+				log.Printf("Level %s has an invalid layout: %s", Key(pack.ID, level.ID), err)
+				test.Fail()
+				continue
+			}
+			if !board.IsInRange(level.Start.Y, level.Start.X) || board.Field[level.Start.Y][level.Start.X] != 0 {
+				log.Printf("Level %s must start on a blank tile. Actual: %+v", Key(pack.ID, level.ID), level.Start)
+				test.Fail()
+			}
+		}
+	}
+}
+
+func TestUnlockRules(test *testing.T) {
+	basics, _, err := Find("basics", "first-steps")
+	if err != nil {
+		log.Printf("Error finding level: %s", err)
+		test.FailNow()
+	}
+	patterns, _, _ := Find("patterns", "one-one")
+	completed := map[string]bool{}
+
+	if !basics.LevelUnlocked("first-steps", completed) || basics.LevelUnlocked("counting", completed) {
+		log.Printf("Expected only the first level of the first pack to be unlocked.")
+		test.Fail()
+	}
+	if patterns.Unlocked(completed) {
+		log.Printf("Expected patterns pack to be locked until basics is completed.")
+		test.Fail()
+	}
+
+	for _, level := range basics.Levels {
+		completed[Key(basics.ID, level.ID)] = true
+	}
+	if !patterns.LevelUnlocked("one-one", completed) {
+		log.Printf("Expected patterns pack to unlock after completing basics.")
+		test.Fail()
+	}
+}
+
+func TestNewGameRevealsStart(test *testing.T) {
+	_, level, _ := Find("basics", "first-steps")
+	newGame, err := level.NewGame()
+	if err != nil {
+		log.Printf("Error creating level game: %s", err)
+		test.FailNow()
+	}
+	if newGame.Revealed[level.Start.Y][level.Start.X] != 0 || len(newGame.Moves) != 1 {
+		log.Printf("Expected start tile to be revealed. Actual: %+v", newGame.Revealed)
+		test.Fail()
+	}
+}
+
+func TestLevelsNeedNoGuesses(test *testing.T) {
+	for _, pack := range Packs() {
+		for _, level := range pack.Levels {
+			board, err := level.Board()
+			if err != nil {
+				continue // Reported by TestLevelsAreValid.
+			}
+			rating := solver.Rate(*board, game.Coordinate{X: level.Start.X, Y: level.Start.Y})
+			if rating.Guesses != 0 {
+				log.Printf("Level %s requires %d guesses.", Key(pack.ID, level.ID), rating.Guesses)
+				test.Fail()
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/deadly990/gominesweeper/challenge"
+	"github.com/deadly990/gominesweeper/solver"
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+	"github.com/go-chi/chi/v5"
+)
+
+func dailyHandler(w http.ResponseWriter, req *http.Request) {
+	date := challenge.Date(time.Now())
+	player := playerName(w, req)
+
+	attempt, err := storage.LoadAttempt(date, player)
+	if err == nil {
+		if attempt.Finished != 0 {
+			http.Redirect(w, req, resultsURL(date), http.StatusSeeOther)
+			return
+		}
+		// Continue the attempt already in progress.
+		gameSave, err := storage.Load(attempt.Game)
+		if err != nil {
+			log.Println("Load:", err)
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		renderGame(w, req, gameSave, gameSave.ToGame(), attempt.Game)
+		return
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		log.Println("LoadAttempt:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	game, err := challenge.NewGame(date)
+	if err != nil {
+		log.Println("NewGame:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	gameName := generateName(rand.Int63())
+	gameSave := storage.FromGame(*game)
+	gameSave.Daily = date
+	gameSave.Player = player
+	gameSave.Difficulty = "daily"
+	gameSave.Started = time.Now().UnixMilli()
+	rating := solver.Rate(game.Board, game.Moves[0].Coordinate)
+	gameSave.Rating = &rating
+	attempt = &storage.Attempt{Player: player, Game: gameName, Started: gameSave.Started}
+	if err := attempt.Save(date); err != nil {
+		log.Println("Attempt#Save:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	renderGame(w, req, gameSave, game, gameName)
+	saveGame(gameSave, gameName)
+}
+
+func resultsHandler(w http.ResponseWriter, req *http.Request) {
+	date := req.Context().Value(DateString).(string)
+	if _, err := challenge.ParseDate(date); err != nil {
+		http.Error(w, fmt.Sprintf("invalid challenge date: %s", date), 404)
+		return
+	}
+	player := playerName(w, req)
+
+	leaderboard, err := storage.DailyLeaderboard(date)
+	if err != nil {
+		log.Println("DailyLeaderboard:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	attempt, err := storage.LoadAttempt(date, player)
+	if err != nil {
+		attempt = nil
+	}
+	challengeData := view.FromChallenge(date, attempt, leaderboard, player)
+	executeTemplate(w, "challenge.html", challengeData)
+}
+
+// Records the result of a finished daily challenge game on the player's attempt.
+func finishAttempt(gameSave *storage.GameSave, won bool) {
+	attempt, err := storage.LoadAttempt(gameSave.Daily, gameSave.Player)
+	if err != nil {
+		log.Println("LoadAttempt:", err)
+		return
+	}
+	attempt.Finished = gameSave.Finished
+	attempt.Won = won
+	if err := attempt.Save(gameSave.Daily); err != nil {
+		log.Println("Attempt#Save:", err)
+	}
+}
+
+func resultsURL(date string) string {
+	return fmt.Sprintf("/challenge/%s/results", date)
+}
+
+func DateCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), DateString, chi.URLParam(req, string(DateString)))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
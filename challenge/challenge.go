@@ -0,0 +1,79 @@
+package challenge
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+// DateLayout is the format used for daily challenge dates.
+const DateLayout = "2006-01-02"
+
+// Returns the challenge date of a time, in UTC so every player shares the same day.
+func Date(t time.Time) string {
+	return t.UTC().Format(DateLayout)
+}
+
+// Parses a challenge date produced by Date.
+func ParseDate(date string) (time.Time, error) {
+	return time.Parse(DateLayout, date)
+}
+
+// Returns the board seed for a challenge date. The seed depends only on the date.
+func Seed(date string) int64 {
+	hash := sha256.Sum256([]byte("gominesweeper-daily-" + date))
+	return int64(binary.LittleEndian.Uint64(hash[:8]) >> 1)
+}
+
+// Returns the mines, width and height of the board for a challenge date.
+// Boards are small and get denser through the week, starting on Monday.
+func Params(day time.Time) (int, int, int) {
+	weekday := (int(day.Weekday()) + 6) % 7 // Monday is 0, Sunday is 6.
+	return 10 + weekday, 9, 9
+}
+
+// Returns the board for a challenge date.
+func Board(date string) (*generation.Board, error) {
+	day, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+	mines, width, height := Params(day)
+	return generation.NewBoard(mines, width, height, Seed(date))
+}
+
+// Returns the tile revealed for the player when a challenge begins. A blank tile is
+// preferred so the challenge opens with some of the board revealed.
+func Start(board generation.Board) game.Coordinate {
+	width, height := board.BoardSize()
+	random := rand.New(rand.NewSource(board.Seed))
+	offset := random.Intn(width * height)
+	fallback := game.Coordinate{}
+	found := false
+	for index := 0; index < width*height; index++ {
+		position := (offset + index) % (width * height)
+		coord := game.Coordinate{X: position % width, Y: position / width}
+		switch value := board.Field[coord.Y][coord.X]; {
+		case value == 0:
+			return coord
+		case value != -9 && !found:
+			fallback, found = coord, true
+		}
+	}
+	return fallback
+}
+
+// Returns a new Game for a challenge date with its start tile already revealed.
+func NewGame(date string) (*game.Game, error) {
+	board, err := Board(date)
+	if err != nil {
+		return nil, err
+	}
+	newGame := game.NewGame(*board)
+	newGame.Move(Start(*board), newGame.Clear)
+	return newGame, nil
+}
@@ -0,0 +1,58 @@
+package challenge
+
+import (
+	"log"
+	"testing"
+	"time"
+)
+
+func TestBoardIsDeterministic(test *testing.T) {
+	first, err := Board("2026-10-19")
+	if err != nil {
+		log.Printf("Error generating daily board: %s", err)
+		test.FailNow()
+	}
+	second, _ := Board("2026-10-19")
+	other, _ := Board("2026-10-20")
+
+	for y, row := range first.Layout() {
+		if row != second.Layout()[y] {
+			log.Printf("Expected the same date to produce the same board.")
+			test.FailNow()
+		}
+	}
+	if first.Seed == other.Seed {
+		log.Printf("Expected different dates to produce different seeds.")
+		test.Fail()
+	}
+}
+
+func TestUpcomingDays(test *testing.T) {
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	for offset := 0; offset < 366; offset++ {
+		date := Date(day.AddDate(0, 0, offset))
+		board, err := Board(date)
+		if err != nil {
+			log.Printf("Error generating daily board for %s: %s", date, err)
+			test.FailNow()
+		}
+		start := Start(*board)
+		if board.Field[start.Y][start.X] == -9 {
+			log.Printf("Daily board for %s starts on a mine: %+v", date, start)
+			test.Fail()
+		}
+	}
+}
+
+func TestParamsByWeekday(test *testing.T) {
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	sunday := monday.AddDate(0, 0, 6)
+	if mines, _, _ := Params(monday); mines != 10 {
+		log.Printf("Expected Monday to have 10 mines. Actual: %d", mines)
+		test.Fail()
+	}
+	if mines, _, _ := Params(sunday); mines != 16 {
+		log.Printf("Expected Sunday to have 16 mines. Actual: %d", mines)
+		test.Fail()
+	}
+}
@@ -0,0 +1,73 @@
+// Bench plays many games with a bot strategy and reports its win rate, guesses and time per game for each
+// difficulty. Seeds are fixed, so two runs differ only where the solver or strategy has changed.
+// With -campaign it instead plays every campaign level from its start tile and fails if any level needs a guess.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/deadly990/gominesweeper/bot"
+	"github.com/deadly990/gominesweeper/campaign"
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+func main() {
+	strategyName := flag.String("strategy", "safest", "strategy to play with: "+strings.Join(bot.StrategyNames, ", "))
+	difficulties := flag.String("difficulty", strings.Join(generation.PresetNames, ","), "comma separated presets to play")
+	games := flag.Int("games", 1000, "games to play of each difficulty")
+	seed := flag.Int64("seed", 1, "seed of the first board")
+	workers := flag.Int("workers", runtime.NumCPU(), "games to play at once")
+	levels := flag.Bool("campaign", false, "check that campaign levels are solved without guessing")
+	flag.Parse()
+
+	strategy, ok := bot.Strategies[*strategyName]
+	if !ok {
+		log.Fatalf("Unknown strategy %s", *strategyName)
+	}
+	if *levels {
+		if failures := checkCampaign(strategy); failures > 0 {
+			fmt.Printf("%d levels needed guesses\n", failures)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Printf("strategy:%s seed:%d\n", *strategyName, *seed)
+	for _, difficulty := range strings.Split(*difficulties, ",") {
+		summary, err := bot.Benchmark(strings.TrimSpace(difficulty), strategy, *seed, *games, *workers)
+		if err != nil {
+			log.Fatal("Benchmark:", err)
+		}
+		fmt.Println(summary)
+	}
+}
+
+// Plays every campaign level and prints those the strategy could not clear without guessing.
+// Returns the number of such levels.
+func checkCampaign(strategy bot.Strategy) int {
+	failures := 0
+	for _, pack := range campaign.Packs() {
+		for _, level := range pack.Levels {
+			key := campaign.Key(pack.ID, level.ID)
+			board, err := level.Board()
+			if err != nil {
+				fmt.Printf("%s: %s\n", key, err)
+				failures++
+				continue
+			}
+			start := game.Coordinate{X: level.Start.X, Y: level.Start.Y}
+			result := bot.Play(*board, strategy, rand.New(rand.NewSource(0)), &start)
+			if result.Guesses > 0 || !result.Won {
+				fmt.Printf("%s: won:%t guesses:%d\n", key, result.Won, result.Guesses)
+				failures++
+			}
+		}
+	}
+	return failures
+}
@@ -0,0 +1,36 @@
+// Daily prints the daily challenge boards for a range of dates. Boards depend only on
+// the date, so upcoming challenges can be checked without running the server.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/deadly990/gominesweeper/challenge"
+)
+
+func main() {
+	from := flag.String("date", challenge.Date(time.Now()), "first challenge date to print (YYYY-MM-DD)")
+	days := flag.Int("days", 1, "number of consecutive days to print")
+	flag.Parse()
+
+	day, err := challenge.ParseDate(*from)
+	if err != nil {
+		log.Fatal("ParseDate:", err)
+	}
+	for offset := 0; offset < *days; offset++ {
+		date := challenge.Date(day.AddDate(0, 0, offset))
+		board, err := challenge.Board(date)
+		if err != nil {
+			log.Fatal("Board:", err)
+		}
+		start := challenge.Start(*board)
+		fmt.Printf("%s seed:%d mines:%d start:%d_%d\n", date, board.Seed, board.Mines, start.Y, start.X)
+		for _, row := range board.Layout() {
+			fmt.Println(row)
+		}
+		fmt.Println()
+	}
+}
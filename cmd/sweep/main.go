@@ -0,0 +1,361 @@
+// Sweep plays minesweeper in the terminal against the same game and generation packages as the server.
+// Move with the arrow keys or hjkl, reveal with space, flag with f, chord with c, start over with n and
+// quit with q. Boards can be replayed by seed or loaded from a layout file to debug them.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/solver"
+	"golang.org/x/term"
+)
+
+// Keys that are not a single printable character.
+const (
+	KeyUp    = "up"
+	KeyDown  = "down"
+	KeyLeft  = "left"
+	KeyRight = "right"
+	KeyEnter = "enter"
+	KeyQuit  = "quit"
+)
+
+// ANSI escape sequences used to draw the board.
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	reset       = "\x1b[0m"
+	inverse     = "\x1b[7m"
+)
+
+// numberColors are the colors of hints 1 to 8, following the classic palette.
+var numberColors = [9]string{"", "\x1b[94m", "\x1b[32m", "\x1b[91m", "\x1b[34m", "\x1b[31m", "\x1b[36m", "\x1b[1m", "\x1b[90m"}
+
+// client is a game in progress and the tile the cursor is on.
+type client struct {
+	game    *game.Game
+	cursor  game.Coordinate
+	started time.Time
+	color   bool
+	// lives is the number of mines each game may hit before it is lost, zero for classic games.
+	lives int
+	// next creates the board for a new game.
+	next func() (*generation.Board, error)
+}
+
+func main() {
+	difficulty := flag.String("difficulty", "beginner", "preset to play: "+strings.Join(generation.PresetNames, ", ")+" or custom")
+	width := flag.Int("width", 9, "board width for custom games")
+	height := flag.Int("height", 9, "board height for custom games")
+	mines := flag.Int("mines", 10, "number of mines for custom games")
+	perTile := flag.Int("per-tile", 1, fmt.Sprintf("most mines a tile can hold, up to %d", generation.MaxMinesPerTile))
+	layers := flag.Int("layers", 1, fmt.Sprintf("layers of a three dimensional board, up to %d, each holding -mines", generation.MaxLayers))
+	seed := flag.Int64("seed", 0, "seed of the first board, random if zero")
+	layout := flag.String("layout", "", "file of rows of . and * to play instead of a generated board")
+	topologyName := flag.String("topology", "plane", "shape of the board: "+strings.Join(generation.TopologyNames, ", "))
+	neighborhoodName := flag.String("neighborhood", "standard", "tiles numbers count: "+strings.Join(generation.NeighborhoodNames, ", ")+" or offsets such as \"0,-1 0,1\"")
+	lives := flag.Int("lives", 0, fmt.Sprintf("mines that can be hit before losing, up to %d, zero for a classic game", game.MaxLives))
+	color := flag.Bool("color", os.Getenv("NO_COLOR") == "", "colorize numbers and flags")
+	flag.Parse()
+
+	if preset, ok := generation.Presets[*difficulty]; ok {
+		*width, *height, *mines = preset.Width, preset.Height, preset.Mines
+	} else if *difficulty != "custom" {
+		log.Fatalf("Unknown difficulty %s", *difficulty)
+	}
+	topology, err := generation.ParseTopology(*topologyName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	neighborhood, err := generation.ParseNeighborhood(*neighborhoodName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	next := func() (*generation.Board, error) {
+		board, err := nextBoard(*layout, *mines*(*layers), *perTile, *width, *height*(*layers), seed)
+		if err != nil {
+			return board, err
+		}
+		if board, err = board.OnTopology(topology).WithNeighborhood(neighborhood); err != nil {
+			return board, err
+		}
+		return board.InLayers(*layers)
+	}
+
+	if *lives < 0 || *lives > game.MaxLives {
+		log.Fatalf("Lives must be between 0 and %d", game.MaxLives)
+	}
+	sweeper := &client{color: *color, lives: *lives, next: next}
+	if err := sweeper.newGame(); err != nil {
+		log.Fatal("NewBoard:", err)
+	}
+	if err := run(sweeper); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Returns the board of the next game: the layout if one was given, otherwise a board generated from the seed,
+// which is used once before boards become random.
+func nextBoard(layout string, mines int, perTile int, width int, height int, seed *int64) (*generation.Board, error) {
+	if layout != "" {
+		return loadLayout(layout)
+	}
+	boardSeed := *seed
+	*seed = 0
+	if boardSeed == 0 {
+		boardSeed = rand.Int63()
+	}
+	return generation.NewMultiMineBoard(mines, perTile, width, height, boardSeed)
+}
+
+// Reads a layout file, ignoring blank lines.
+func loadLayout(path string) (*generation.Board, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rows := []string{}
+	for _, row := range strings.Split(string(contents), "\n") {
+		if row = strings.TrimSpace(row); row != "" {
+			rows = append(rows, row)
+		}
+	}
+	return generation.FromLayout(rows)
+}
+
+// Puts the terminal in raw mode and plays until the player quits, redrawing on every key and once a second
+// for the clock.
+func run(sweeper *client) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("sweep must be run in a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	fmt.Print(hideCursor)
+	defer fmt.Print(showCursor, reset, "\r\n")
+
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			key, err := readKey(reader)
+			if err != nil {
+				return
+			}
+			keys <- key
+		}
+	}()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	fmt.Print(clearScreen, sweeper.render())
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || key == KeyQuit {
+				return nil
+			}
+			if err := sweeper.handle(key); err != nil {
+				return err
+			}
+		case <-ticker.C:
+		}
+		fmt.Print(clearScreen, sweeper.render())
+	}
+}
+
+// Reads one key press, decoding arrow key escape sequences. Ctrl-C, Ctrl-D and q quit.
+func readKey(reader *bufio.Reader) (string, error) {
+	char, err := reader.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch char {
+	case 3, 4, 'q':
+		return KeyQuit, nil
+	case '\r', '\n':
+		return KeyEnter, nil
+	case 0x1b:
+		if reader.Buffered() < 2 {
+			return string(char), nil
+		}
+		if next, _ := reader.ReadByte(); next != '[' {
+			return string(next), nil
+		}
+		arrow, _ := reader.ReadByte()
+		switch arrow {
+		case 'A':
+			return KeyUp, nil
+		case 'B':
+			return KeyDown, nil
+		case 'C':
+			return KeyRight, nil
+		case 'D':
+			return KeyLeft, nil
+		}
+		return string(arrow), nil
+	}
+	return string(char), nil
+}
+
+// Starts a game on a new board with the cursor on the tile the solver would open first.
+func (sweeper *client) newGame() error {
+	board, err := sweeper.next()
+	if err != nil {
+		return err
+	}
+	sweeper.game = game.NewGame(*board)
+	sweeper.game.Lives = sweeper.lives
+	sweeper.cursor = solver.DefaultStart(*board)
+	sweeper.started = time.Time{}
+	return nil
+}
+
+// Applies a key press to the game.
+func (sweeper *client) handle(key string) error {
+	width, height := sweeper.game.Board.BoardSize()
+	switch key {
+	case KeyUp, "k", "w":
+		sweeper.cursor.Y = max(sweeper.cursor.Y-1, 0)
+	case KeyDown, "j", "s":
+		sweeper.cursor.Y = min(sweeper.cursor.Y+1, height-1)
+	case KeyLeft, "h", "a":
+		sweeper.cursor.X = max(sweeper.cursor.X-1, 0)
+	case KeyRight, "l", "d":
+		sweeper.cursor.X = min(sweeper.cursor.X+1, width-1)
+	case " ", KeyEnter:
+		// Revealed numbers are chorded, so the whole game can be played with one key.
+		if sweeper.game.Revealed[sweeper.cursor.Y][sweeper.cursor.X] >= 0 {
+			sweeper.play(game.Chord)
+		} else {
+			sweeper.play(game.Reveal)
+		}
+	case "f":
+		sweeper.play(game.Flag)
+	case "c":
+		sweeper.play(game.Chord)
+	case "n":
+		return sweeper.newGame()
+	}
+	return nil
+}
+
+// Plays an action at the cursor, starting the clock on the first move.
+func (sweeper *client) play(action game.Action) {
+	if sweeper.started.IsZero() {
+		sweeper.started = time.Now()
+	}
+	sweeper.game.Play(game.Move{Coordinate: sweeper.cursor, Action: action, Elapsed: time.Since(sweeper.started)})
+}
+
+// Returns the board and status line, with lines ending in carriage returns for a terminal in raw mode.
+func (sweeper *client) render() string {
+	var builder strings.Builder
+	current := sweeper.game
+	grid := current.Board.Grid()
+	for y, row := range current.Revealed {
+		if _, layerRow := grid.Layer(y); layerRow == 0 && y > 0 {
+			builder.WriteString("\r\n")
+		}
+		// Odd rows of a hex board sit half a tile to the right, so each tile touches the two above and below it.
+		if current.Board.Topology == generation.Hex && y%2 == 1 {
+			builder.WriteString(" ")
+		}
+		for x, value := range row {
+			flags := current.FlagsAt(game.Coordinate{X: x, Y: y})
+			cell := sweeper.cell(value, flags, current.Over())
+			if current.Board.Counts != nil {
+				// Numbers and flag counts next to stacked mines can take two characters, so every tile does.
+				cell = strings.Repeat(" ", 2-visibleWidth(value, flags, current.Over())) + cell
+			}
+			if sweeper.cursor == (game.Coordinate{X: x, Y: y}) {
+				cell = inverse + cell + reset
+			}
+			builder.WriteString(" " + cell)
+		}
+		builder.WriteString("\r\n")
+	}
+	builder.WriteString("\r\n" + sweeper.status() + "\r\n")
+	builder.WriteString("arrows/hjkl move  space reveal  f flag  c chord  n new  q quit\r\n")
+	return builder.String()
+}
+
+// Returns the characters drawn for a tile. Mines are shown once the game is over.
+func (sweeper *client) cell(value int, flags int, over bool) string {
+	switch {
+	case value == game.Exploded:
+		return sweeper.paint("\x1b[41m", "*")
+	case flags > 0 && over && value != -9:
+		return sweeper.paint("\x1b[91m", "x")
+	case flags > 1:
+		return sweeper.paint("\x1b[91m", fmt.Sprintf("F%d", flags))
+	case flags > 0:
+		return sweeper.paint("\x1b[91m", "F")
+	case value == -9 && over:
+		return "*"
+	case value < 0:
+		return "."
+	case value == 0:
+		return " "
+	}
+	return sweeper.paint(numberColors[min(value, 8)], fmt.Sprint(value))
+}
+
+// Returns the number of characters a tile is drawn with, not counting colors.
+func visibleWidth(value int, flags int, over bool) int {
+	if (flags > 1 && !(over && value != -9)) || (value >= 10 && value != game.Exploded) {
+		return 2
+	}
+	return 1
+}
+
+func (sweeper *client) paint(color string, text string) string {
+	if !sweeper.color {
+		return text
+	}
+	return color + text + reset
+}
+
+// Returns the mines left to flag, the time played, the cursor position and the outcome.
+func (sweeper *client) status() string {
+	current := sweeper.game
+	flags := current.FlagTotal()
+	elapsed := time.Duration(0)
+	if !sweeper.started.IsZero() {
+		elapsed = time.Since(sweeper.started)
+		if current.Over() && len(current.Moves) > 0 {
+			elapsed = current.Moves[len(current.Moves)-1].Elapsed
+		}
+	}
+	outcome := "playing"
+	if current.Won() {
+		outcome = "cleared! n for a new game"
+	} else if current.Lost() {
+		outcome = "exploded. n for a new game"
+	}
+	position := fmt.Sprintf("(%d, %d)", sweeper.cursor.X, sweeper.cursor.Y)
+	if current.Board.Layers > 1 {
+		layer, row := sweeper.cursor.Layer(current.Board.Grid())
+		position = fmt.Sprintf("(%d, %d) layer %d", sweeper.cursor.X, row, layer+1)
+	}
+	if current.Lives > 0 {
+		outcome = fmt.Sprintf("%s  Lives: %d", outcome, current.LivesLeft())
+	}
+	return fmt.Sprintf("Mines: %d  Time: %.0fs  Seed: %d  %s  %s",
+		current.Board.Mines-flags, elapsed.Seconds(), current.Board.Seed, position, outcome)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+func TestReadKey(test *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("\x1b[A\x1b[Cf \rq"))
+	expected := []string{KeyUp, KeyRight, "f", " ", KeyEnter, KeyQuit}
+	for _, want := range expected {
+		if key, err := readKey(reader); err != nil || key != want {
+			log.Printf("Expected key %q. Actual: %q %v", want, key, err)
+			test.Fail()
+		}
+	}
+}
+
+func TestHandleKeys(test *testing.T) {
+	sweeper := &client{next: func() (*generation.Board, error) {
+		return generation.FromLayout([]string{
+			"*..",
+			"...",
+			"...",
+		})
+	}}
+	if err := sweeper.newGame(); err != nil {
+		log.Printf("Error starting game: %s", err)
+		test.FailNow()
+	}
+	sweeper.cursor = game.Coordinate{X: 0, Y: 0}
+	sweeper.handle(KeyUp)
+	sweeper.handle(KeyLeft)
+	if sweeper.cursor != (game.Coordinate{X: 0, Y: 0}) {
+		log.Printf("Expected the cursor to stay on the board. Actual: %+v", sweeper.cursor)
+		test.Fail()
+	}
+
+	sweeper.handle("f")
+	if !sweeper.game.Flagged(game.Coordinate{X: 0, Y: 0}) || !strings.Contains(sweeper.render(), "Mines: 0") {
+		log.Printf("Expected the mine to be flagged. Actual: %s", sweeper.render())
+		test.Fail()
+	}
+
+	sweeper.handle(KeyDown)
+	sweeper.handle(KeyDown)
+	sweeper.handle(KeyRight)
+	sweeper.handle(KeyRight)
+	sweeper.handle(" ")
+	if !sweeper.game.Won() || !strings.Contains(sweeper.render(), "cleared") {
+		log.Printf("Expected revealing the far corner to clear the board. Actual: %s", sweeper.render())
+		test.Fail()
+	}
+}
@@ -13,17 +13,30 @@ const (
 )
 
 type ClickCommand struct {
-	Type        ClickType
-	YCoordinate int
-	XCoordinate int
+	Type        ClickType `json:"type"`
+	YCoordinate int       `json:"y"`
+	XCoordinate int       `json:"x"`
+	// Flagged is the flag state wanted by a right click. When set, a tile already in that state is left alone
+	// rather than toggled.
+	Flagged *bool `json:"flagged,omitempty"`
+}
+
+// Returns the game action performed by a click type. Left clicks reveal, right clicks flag and middle clicks chord.
+func (clickType ClickType) Action() game.Action {
+	switch clickType {
+	case rightClick:
+		return game.Flag
+	case middleClick:
+		return game.Chord
+	default:
+		return game.Reveal
+	}
 }
 
 func RunClickCommand(gameInstance game.Game, command ClickCommand) game.Game {
-	// switch command.Type {
-	// case leftClick:
-	// case rightClick:
-	// case middleClick:
-	// }
-	gameInstance.Move(game.Coordinate{X: command.XCoordinate, Y: command.YCoordinate}, gameInstance.Clear)
+	gameInstance.Play(game.Move{
+		Coordinate: game.Coordinate{X: command.XCoordinate, Y: command.YCoordinate},
+		Action:     command.Type.Action(),
+	})
 	return gameInstance
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+// Adds the requesting player to a cooperative game that has not finished.
+func joinGameHandler(w http.ResponseWriter, req *http.Request) {
+	gameCtx := req.Context().Value(GameIDString).(string)
+	unlock := lockGame(gameCtx)
+	defer unlock()
+	gameSave, err := storage.Load(gameCtx)
+	if err != nil {
+		http.Error(w, "game not found", 404)
+		return
+	}
+	if !gameSave.Coop || gameSave.Finished != 0 {
+		http.Error(w, "only cooperative games in progress can be joined", 409)
+		return
+	}
+	if !canView(w, req, gameSave) {
+		http.Error(w, errNoSpectators.Error(), 403)
+		return
+	}
+	player := playerName(w, req)
+	if !slices.Contains(gameSave.Contributors, player) {
+		gameSave.Contributors = append(gameSave.Contributors, player)
+		saveGame(gameSave, gameCtx)
+		log.Printf("Game: %s joined by %s", gameCtx, player)
+	}
+	http.Redirect(w, req, "/game/"+gameCtx, http.StatusSeeOther)
+}
@@ -1,5 +1,10 @@
 package game
 
+import "github.com/deadly990/gominesweeper/generation"
+
+// Coordinate is the column X and row Y of a tile. On hex boards it is an offset coordinate, with odd rows
+// shifted half a tile right, so the tiles around it depend on the board's topology rather than on X and Y alone.
+// On layered boards Y counts the rows of every layer in turn, and Layer splits it into a layer and a row.
 type Coordinate struct {
 	X int
 	Y int
@@ -10,13 +15,17 @@ func (coord Coordinate) Offset(xOffset int, yOffset int) Coordinate {
 	return Coordinate{coord.X + xOffset, coord.Y + yOffset}
 }
 
-// Returns a slice containing adjacent Coordinates to input param.
-func (coord Coordinate) Adjacent() []Coordinate {
+// Returns the Coordinates adjacent to input param on a grid, excluding the Coordinate itself.
+func (coord Coordinate) Adjacent(grid generation.Grid) []Coordinate {
 	neighbors := []Coordinate{}
-	for yOffset := -1; yOffset <= 1; yOffset++ {
-		for xOffset := -1; xOffset <= 1; xOffset++ {
-			neighbors = append(neighbors, coord.Offset(xOffset, yOffset))
-		}
+	for _, neighbor := range grid.Neighbors(coord.Y, coord.X) {
+		neighbors = append(neighbors, Coordinate{neighbor[1], neighbor[0]})
 	}
 	return neighbors
 }
+
+// Returns the layer of a grid the Coordinate is in and its row within that layer. Boards without layers have
+// a single layer, numbered 0.
+func (coord Coordinate) Layer(grid generation.Grid) (int, int) {
+	return grid.Layer(coord.Y)
+}
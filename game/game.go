@@ -1,46 +1,239 @@
 package game
 
 import (
+	"slices"
+	"time"
+
 	"github.com/deadly990/gominesweeper/generation"
 )
 
+// Action is the kind of click a move was made with.
+type Action string
+
+const (
+	// Reveal clears a hidden tile, flooding outwards from blank tiles.
+	Reveal Action = "reveal"
+	// Flag toggles a flag on a hidden tile.
+	Flag Action = "flag"
+	// Chord reveals the unflagged neighbors of a number touching as many flags as it shows.
+	Chord Action = "chord"
+)
+
+// Move is a single click made during a game.
+type Move struct {
+	Coordinate
+	Action Action
+	// Elapsed is the time since the game started that the move was made.
+	Elapsed time.Duration
+	// Useful is true if the move changed the board.
+	Useful bool
+	// Cleared is the number of tiles the move revealed.
+	Cleared int
+	// Player made the move in a cooperative game, empty otherwise.
+	Player string
+}
+
+// Exploded is the value of a revealed mine. It is above any number a tile can show, even with stacked mines on
+// a layered board.
+const Exploded = 999
+
+// hiddenBlank is the value of an unrevealed blank tile.
+const hiddenBlank = -10
+
 type Game struct {
 	Board    generation.Board
 	Revealed [][]int
-	Moves    []Coordinate
+	Flags    [][]bool
+	Moves    []Move
+	// FlagCounts holds the number of flags on each tile of boards that stack mines, and is nil otherwise.
+	FlagCounts [][]int
+	// Lives is the number of mines a player may hit before the game is lost, zero for classic games that are
+	// lost on the first. Hits are the mines hit so far, which were flagged in place of ending the game.
+	Lives int
+	Hits  []Coordinate
 }
 
+// MaxLives is the most lives a game can start with.
+const MaxLives = 5
+
 func NewGame(board generation.Board) *Game {
 	width, height := board.BoardSize()
 
 	var revealed = make([][]int, height)
+	var flags = make([][]bool, height)
 	for i := 0; i < height; i++ {
 		revealed[i] = make([]int, width)
+		flags[i] = make([]bool, width)
 	}
 
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
-			switch value := board.Field[y][x]; value {
-			case 0:
-				revealed[y][x] = -10
-			case 1, 2, 3, 4, 5, 6, 7, 8:
-				revealed[y][x] = -value
-			case -9:
-				revealed[y][x] = value
-			}
+			revealed[y][x] = hide(board.Field[y][x])
 		}
 	}
 
-	return &Game{board, revealed, []Coordinate{}}
+	game := &Game{board, revealed, flags, []Move{}, nil, 0, nil}
+	if board.Counts != nil {
+		game.FlagCounts = make([][]int, height)
+		for i := range game.FlagCounts {
+			game.FlagCounts[i] = make([]int, width)
+		}
+	}
+	return game
+}
+
+// Returns the value an unrevealed tile is stored with: -10 for a blank, -9 for a mine and the negated number
+// otherwise. Numbers above 8, only found next to stacked mines, skip past -9 and -10.
+func hide(value int) int {
+	switch {
+	case value == 0:
+		return hiddenBlank
+	case value == -9:
+		return value
+	case value > 8:
+		return -value - 2
+	}
+	return -value
+}
+
+// Returns the value of a tile once revealed from the value it was hidden with.
+func show(hidden int) int {
+	switch {
+	case hidden == hiddenBlank:
+		return 0
+	case hidden == -9:
+		return Exploded
+	case hidden < hiddenBlank:
+		return -hidden - 2
+	}
+	return -hidden
 }
 
 func (game *Game) tileValue(coord Coordinate) *int {
 	return &(game.Revealed[coord.Y][coord.X])
 }
 
+// Applies an action at a Coordinate and records it as a reveal. Moves made after the game is over are ignored.
 func (game *Game) Move(coord Coordinate, action func(Coordinate)) {
+	if game.Over() {
+		return
+	}
+	before := game.revealedCount()
 	action(coord)
-	game.Moves = append(game.Moves, coord)
+	game.Moves = append(game.Moves, Move{Coordinate: coord, Action: Reveal, Useful: game.revealedCount() != before})
+}
+
+// Applies a move and records it. Moves made after the game is over or outside the board are ignored.
+// Returns true if the move changed the board.
+func (game *Game) Play(move Move) bool {
+	if game.Over() || !game.Board.IsInRange(move.Y, move.X) {
+		return false
+	}
+	before := game.revealedCount()
+	switch move.Action {
+	case Flag:
+		move.Useful = game.ToggleFlag(move.Coordinate)
+	case Chord:
+		move.Useful = game.Chord(move.Coordinate)
+	default:
+		move.Action = Reveal
+		move.Useful = game.Reveal(move.Coordinate)
+	}
+	move.Cleared = game.revealedCount() - before
+	game.Moves = append(game.Moves, move)
+	return move.Useful
+}
+
+// Clears a hidden, unflagged tile. Returns true if the tile was cleared.
+func (game *Game) Reveal(coord Coordinate) bool {
+	if game.isRevealed(coord) || game.Flags[coord.Y][coord.X] {
+		return false
+	}
+	game.Clear(coord)
+	return true
+}
+
+// Toggles the flag on a hidden tile. On boards that stack mines, each toggle adds a flag until the tile has
+// as many as it can hold mines, and the next clears them. Returns true if the tile was hidden.
+func (game *Game) ToggleFlag(coord Coordinate) bool {
+	if game.isRevealed(coord) || game.WasHit(coord) {
+		return false
+	}
+	if game.FlagCounts != nil {
+		count := &game.FlagCounts[coord.Y][coord.X]
+		*count = (*count + 1) % (game.Board.MinesPerTile + 1)
+		game.Flags[coord.Y][coord.X] = *count > 0
+		return true
+	}
+	game.Flags[coord.Y][coord.X] = !game.Flags[coord.Y][coord.X]
+	return true
+}
+
+// Returns the number of flags on a tile.
+func (game *Game) FlagsAt(coord Coordinate) int {
+	if game.FlagCounts != nil {
+		return game.FlagCounts[coord.Y][coord.X]
+	}
+	if game.Flags[coord.Y][coord.X] {
+		return 1
+	}
+	return 0
+}
+
+// Returns the number of flags placed on the board.
+func (game *Game) FlagTotal() int {
+	total := 0
+	for y, row := range game.Flags {
+		for x := range row {
+			total += game.FlagsAt(Coordinate{x, y})
+		}
+	}
+	return total
+}
+
+// Clears every hidden, unflagged neighbor of a revealed number that touches as many flags as it shows.
+// Returns true if any tile was cleared.
+func (game *Game) Chord(coord Coordinate) bool {
+	value := *game.tileValue(coord)
+	if value < 1 || value == Exploded {
+		return false
+	}
+	flags := 0
+	hidden := []Coordinate{}
+	for _, adjacent := range game.adjacent(coord) {
+		if game.isRevealed(adjacent) {
+			continue
+		}
+		if game.Flags[adjacent.Y][adjacent.X] {
+			flags += game.FlagsAt(adjacent)
+		} else {
+			hidden = append(hidden, adjacent)
+		}
+	}
+	if flags != value || len(hidden) == 0 {
+		return false
+	}
+	for _, adjacent := range hidden {
+		game.Clear(adjacent)
+	}
+	return true
+}
+
+// Returns true if a tile has been flagged.
+func (game *Game) Flagged(coord Coordinate) bool {
+	return game.Flags[coord.Y][coord.X]
+}
+
+func (game *Game) revealedCount() int {
+	count := 0
+	for _, row := range game.Revealed {
+		for _, value := range row {
+			if value >= 0 {
+				count++
+			}
+		}
+	}
+	return count
 }
 
 // Clears a tile at position (x, y)
@@ -50,8 +243,8 @@ func (game *Game) Clear(coord Coordinate) {
 	for len(queue) > 0 {
 		queuedCoord := queue[0]
 		queue = queue[1:]
-		if *game.tileValue(queuedCoord) == -10 {
-			for _, adjacent := range queuedCoord.Adjacent() {
+		if *game.tileValue(queuedCoord) == hiddenBlank {
+			for _, adjacent := range game.adjacent(queuedCoord) {
 				if game.isValidClear(adjacent) {
 					queue = append(queue, adjacent)
 				}
@@ -61,8 +254,13 @@ func (game *Game) Clear(coord Coordinate) {
 	}
 }
 
+// Returns the tiles adjacent to a Coordinate on the game's board.
+func (game *Game) adjacent(coord Coordinate) []Coordinate {
+	return coord.Adjacent(game.Board.Grid())
+}
+
 func (game *Game) isValidClear(coord Coordinate) bool {
-	return game.Board.IsInRange(coord.Y, coord.X) && !game.isRevealed(coord)
+	return game.Board.IsInRange(coord.Y, coord.X) && !game.isRevealed(coord) && !game.Flags[coord.Y][coord.X]
 }
 
 func (game *Game) isRevealed(coord Coordinate) bool {
@@ -70,10 +268,79 @@ func (game *Game) isRevealed(coord Coordinate) bool {
 }
 
 func (game *Game) revealTileValue(coord Coordinate) {
-	switch value := game.Revealed[coord.Y][coord.X]; value {
-	case -10: // Unrevealed blank tile.
-		*game.tileValue(coord) = 0
-	case -1, -2, -3, -4, -5, -6, -7, -8, -9:
-		*game.tileValue(coord) = -value
+	value := game.tileValue(coord)
+	if *value == -9 && len(game.Hits)+1 < game.Lives {
+		// The hit costs a life rather than the game, and the mine is flagged so it cannot be hit again.
+		game.Hits = append(game.Hits, coord)
+		game.Flags[coord.Y][coord.X] = true
+		if game.FlagCounts != nil {
+			game.FlagCounts[coord.Y][coord.X] = game.Board.MinesAt(coord.Y, coord.X)
+		}
+		return
+	}
+	if *value == -9 && game.Lives > 0 {
+		game.Hits = append(game.Hits, coord)
+	}
+	if *value < 0 {
+		*value = show(*value)
+	}
+}
+
+// Returns true if a mine was hit at a Coordinate at the cost of a life.
+func (game *Game) WasHit(coord Coordinate) bool {
+	return slices.Contains(game.Hits, coord)
+}
+
+// Returns the number of lives left, zero for classic games.
+func (game *Game) LivesLeft() int {
+	return max(0, game.Lives-len(game.Hits))
+}
+
+// Returns true if a mine has been revealed.
+func (game *Game) Lost() bool {
+	for _, row := range game.Revealed {
+		for _, value := range row {
+			if value == Exploded {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns true if every tile that is not a mine has been revealed.
+func (game *Game) Won() bool {
+	for _, row := range game.Revealed {
+		for _, value := range row {
+			if value < 0 && value != -9 {
+				return false
+			}
+		}
+	}
+	return !game.Lost()
+}
+
+// Returns true if the game has been won or lost.
+func (game *Game) Over() bool {
+	return game.Won() || game.Lost()
+}
+
+// Returns the fraction of the tiles that are not mines which have been revealed.
+func (game *Game) Cleared() float64 {
+	safe, revealed := 0, 0
+	for y, row := range game.Board.Field {
+		for x, value := range row {
+			if value == -9 {
+				continue
+			}
+			safe++
+			if game.Revealed[y][x] >= 0 {
+				revealed++
+			}
+		}
+	}
+	if safe == 0 {
+		return 1
 	}
+	return float64(revealed) / float64(safe)
 }
@@ -82,3 +82,274 @@ func TestDiagonalCornerReveal(test *testing.T) {
 		test.Fail()
 	}
 }
+
+func TestWonAndLost(test *testing.T) {
+	var board generation.Board
+	board.Field = [][]int{
+		{-9, 1, 0}, // [-9, 1, 0]
+		{1, 1, 0},  // [ 1, 1, 0]
+		{0, 0, 0},  // [ 0, 0, 0]
+	}
+
+	game := *NewGame(board)
+	game.Move(Coordinate{2, 0}, game.Clear)
+	if !game.Won() || game.Lost() {
+		log.Printf("Expected game to be won after clearing every safe tile.")
+		test.Fail()
+	}
+
+	game = *NewGame(board)
+	game.Move(Coordinate{0, 0}, game.Clear)
+	if game.Won() || !game.Lost() {
+		log.Printf("Expected game to be lost after revealing a mine.")
+		test.Fail()
+	}
+	game.Move(Coordinate{2, 0}, game.Clear)
+	if len(game.Moves) != 1 || game.Revealed[0][2] != -10 {
+		log.Printf("Expected moves after a loss to be ignored. Actual: %+v", game.Moves)
+		test.Fail()
+	}
+}
+
+func TestFlagAndChord(test *testing.T) {
+	var board generation.Board
+	board.Field = [][]int{
+		{-9, 1, 0}, // [-9, 1, 0]
+		{1, 1, 0},  // [ 1, 1, 0]
+		{0, 0, 0},  // [ 0, 0, 0]
+	}
+
+	game := *NewGame(board)
+	if game.Play(Move{Coordinate: Coordinate{1, 1}, Action: Chord}) {
+		log.Printf("Expected a chord on a hidden tile to do nothing.")
+		test.Fail()
+	}
+	game.Play(Move{Coordinate: Coordinate{1, 1}, Action: Reveal})
+	game.Play(Move{Coordinate: Coordinate{0, 0}, Action: Flag})
+	if !game.Flagged(Coordinate{0, 0}) {
+		log.Printf("Expected 0_0 to be flagged.")
+		test.FailNow()
+	}
+	if game.Play(Move{Coordinate: Coordinate{0, 0}, Action: Reveal}) {
+		log.Printf("Expected revealing a flagged tile to do nothing.")
+		test.Fail()
+	}
+	if !game.Play(Move{Coordinate: Coordinate{1, 1}, Action: Chord}) || !game.Won() {
+		log.Printf("Expected a chord on a satisfied 1 to clear the board. Actual: %+v", game.Revealed)
+		test.Fail()
+	}
+}
+
+func TestTorusClearWraps(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"..*..",
+		"..*..",
+		"..*..",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	plane := NewGame(*board)
+	plane.Play(Move{Coordinate: Coordinate{0, 0}})
+	torus := NewGame(*board.OnTopology(generation.Torus))
+	torus.Play(Move{Coordinate: Coordinate{0, 0}})
+	// On a plane the mines wall off the left side. On a torus the blank tiles reach around the edge.
+	if plane.Revealed[0][4] >= 0 || torus.Revealed[0][4] < 0 || !torus.Won() {
+		log.Printf("Expected the torus to clear across its edge. Plane: %v Torus: %v", plane.Revealed, torus.Revealed)
+		test.Fail()
+	}
+}
+
+func TestHexClear(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"....",
+		".*..",
+		"....",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	hex := NewGame(*board.OnTopology(generation.Hex))
+	// The mine in the odd row touches (0, 1) and (0, 2) above it, but not (0, 0) as it would on a square board.
+	if hex.Revealed[0][0] != -10 || hex.Revealed[0][2] != -1 || hex.Revealed[2][0] != -10 {
+		log.Printf("Hints did not follow hex neighbors. Actual: %v", hex.Revealed)
+		test.Fail()
+	}
+	hex.Play(Move{Coordinate: Coordinate{3, 0}})
+	if hex.Revealed[2][2] != 1 || hex.Revealed[0][0] >= 0 {
+		log.Printf("Expected the opening to stop at the hints around the mine. Actual: %v", hex.Revealed)
+		test.Fail()
+	}
+	hex.Play(Move{Coordinate: Coordinate{0, 0}})
+	hex.Play(Move{Coordinate: Coordinate{0, 2}})
+	if !hex.Won() {
+		log.Printf("Expected the openings to clear every safe hexagon. Actual: %v", hex.Revealed)
+		test.Fail()
+	}
+}
+
+func TestNeighborhoodClear(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"...",
+		".*.",
+		"...",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	orthogonal, err := board.WithNeighborhood(generation.Neighborhoods["orthogonal"])
+	if err != nil {
+		log.Printf("Error applying neighborhood: %s", err)
+		test.FailNow()
+	}
+	current := NewGame(*orthogonal)
+	// Corners do not count the diagonal mine, so a corner opens onto the numbers beside it but not past them.
+	current.Play(Move{Coordinate: Coordinate{0, 0}})
+	if current.Revealed[0][0] != 0 || current.Revealed[0][1] != 1 || current.Revealed[0][2] >= 0 {
+		log.Printf("Expected the corner opening to stop at the orthogonal numbers. Actual: %v", current.Revealed)
+		test.Fail()
+	}
+	current.Play(Move{Coordinate: Coordinate{1, 0}, Action: Chord})
+	if current.Revealed[0][2] >= 0 {
+		log.Printf("Expected a chord without flags to do nothing. Actual: %v", current.Revealed)
+		test.Fail()
+	}
+}
+
+func TestStackedMines(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"3.4",
+		"...",
+		"...",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	game := *NewGame(*board)
+	game.Play(Move{Coordinate: Coordinate{1, 1}, Action: Reveal})
+	if game.Revealed[1][1] != 7 {
+		log.Printf("Expected the middle to count 7 mines. Actual: %v", game.Revealed)
+		test.FailNow()
+	}
+	// Flags cycle from 1 up to the most a tile can hold, then clear.
+	for count := 1; count <= generation.MaxMinesPerTile; count++ {
+		game.Play(Move{Coordinate: Coordinate{2, 0}, Action: Flag})
+		if game.FlagsAt(Coordinate{2, 0}) != count {
+			log.Printf("Expected %d flags on 2_0. Actual: %d", count, game.FlagsAt(Coordinate{2, 0}))
+			test.Fail()
+		}
+	}
+	game.Play(Move{Coordinate: Coordinate{2, 0}, Action: Flag})
+	if game.Flagged(Coordinate{2, 0}) || game.FlagTotal() != 0 {
+		log.Printf("Expected the flags on 2_0 to clear. Actual: %d", game.FlagTotal())
+		test.Fail()
+	}
+	for range 3 {
+		game.Play(Move{Coordinate: Coordinate{0, 0}, Action: Flag})
+	}
+	for range 4 {
+		game.Play(Move{Coordinate: Coordinate{2, 0}, Action: Flag})
+	}
+	if game.FlagTotal() != 7 {
+		log.Printf("Expected 7 flags. Actual: %d", game.FlagTotal())
+		test.Fail()
+	}
+	if !game.Play(Move{Coordinate: Coordinate{1, 1}, Action: Chord}) || !game.Won() {
+		log.Printf("Expected a chord on a satisfied 7 to clear the board. Actual: %v", game.Revealed)
+		test.Fail()
+	}
+
+	// Numbers above 8 are hidden and shown like any other.
+	board, err = generation.FromLayout([]string{"444", "4.4", "444"})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	game = *NewGame(*board)
+	game.Play(Move{Coordinate: Coordinate{1, 1}, Action: Reveal})
+	if game.Revealed[1][1] != 32 || !game.Won() {
+		log.Printf("Expected revealing the middle to show 32 and win. Actual: %v", game.Revealed)
+		test.Fail()
+	}
+	game = *NewGame(*board)
+	game.Play(Move{Coordinate: Coordinate{0, 0}, Action: Reveal})
+	if game.Revealed[0][0] != Exploded || !game.Lost() {
+		log.Printf("Expected revealing a stack to explode. Actual: %v", game.Revealed)
+		test.Fail()
+	}
+}
+
+func TestLayeredClear(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"...",
+		"...",
+		"...",
+		"...",
+		"...",
+		"...",
+		"...",
+		"...",
+		"..*",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	cube, err := board.InLayers(3)
+	if err != nil {
+		log.Printf("Error cutting board into layers: %s", err)
+		test.FailNow()
+	}
+	game := NewGame(*cube)
+	// An opening in the top layer spreads down through the layers until it reaches the corner mine.
+	game.Play(Move{Coordinate: Coordinate{0, 0}})
+	if !game.Won() {
+		log.Printf("Expected the opening to clear every layer. Actual: %v", game.Revealed)
+		test.Fail()
+	}
+	if game.Revealed[4][1] != 1 || game.Revealed[1][1] != 0 {
+		log.Printf("Expected only the middle layer and the bottom to count the mine. Actual: %v", game.Revealed)
+		test.Fail()
+	}
+	if layer, row := (Coordinate{2, 8}).Layer(cube.Grid()); layer != 2 || row != 2 {
+		log.Printf("Expected the mine to be in the last row of the bottom layer. Actual: row %d of layer %d", row, layer)
+		test.Fail()
+	}
+}
+
+func TestLives(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"*.*",
+		"...",
+		"*..",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	game := NewGame(*board)
+	game.Lives = 3
+	game.Play(Move{Coordinate: Coordinate{0, 0}})
+	if game.Lost() || !game.Flagged(Coordinate{0, 0}) || !game.WasHit(Coordinate{0, 0}) || game.LivesLeft() != 2 {
+		log.Printf("Expected the first hit to cost a life and flag the mine. Actual: %v, %d lives", game.Revealed, game.LivesLeft())
+		test.FailNow()
+	}
+	if game.Play(Move{Coordinate: Coordinate{0, 0}, Action: Flag}) {
+		log.Printf("Expected the flag on a hit mine to stay.")
+		test.Fail()
+	}
+	game.Play(Move{Coordinate: Coordinate{2, 0}})
+	if game.Lost() || game.LivesLeft() != 1 {
+		log.Printf("Expected the second hit to cost a life. Actual: %d lives", game.LivesLeft())
+		test.Fail()
+	}
+	game.Play(Move{Coordinate: Coordinate{0, 2}})
+	if !game.Lost() || game.LivesLeft() != 0 || game.Revealed[2][0] != Exploded {
+		log.Printf("Expected the last life to end the game. Actual: %v", game.Revealed)
+		test.Fail()
+	}
+}
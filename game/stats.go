@@ -0,0 +1,113 @@
+package game
+
+import "time"
+
+// Stats are the metrics competitive players compare for a game.
+type Stats struct {
+	// Clicks by type.
+	Reveals int `json:"reveals"`
+	Flags   int `json:"flags"`
+	Chords  int `json:"chords"`
+	// Useful clicks changed the board, wasted clicks did not.
+	Useful int `json:"useful"`
+	Wasted int `json:"wasted"`
+	// ThreeBV is the 3BV of the board and Solved the part of it cleared so far.
+	ThreeBV int `json:"3bv"`
+	Solved  int `json:"solved3bv"`
+	// Efficiency is the 3BV solved per click.
+	Efficiency       float64       `json:"efficiency"`
+	Elapsed          time.Duration `json:"elapsed"`
+	ThreeBVPerSecond float64       `json:"3bvPerSecond"`
+	ClicksPerSecond  float64       `json:"clicksPerSecond"`
+}
+
+// Returns the total number of clicks.
+func (stats Stats) Clicks() int {
+	return stats.Reveals + stats.Flags + stats.Chords
+}
+
+// Returns the metrics of a game so far. Time based rates are measured up to the last move.
+func (game *Game) Stats() Stats {
+	stats := Stats{ThreeBV: game.Board.ThreeBV(), Solved: game.solvedThreeBV()}
+	for _, move := range game.Moves {
+		switch move.Action {
+		case Flag:
+			stats.Flags++
+		case Chord:
+			stats.Chords++
+		default:
+			stats.Reveals++
+		}
+		if move.Useful {
+			stats.Useful++
+		} else {
+			stats.Wasted++
+		}
+		stats.Elapsed = move.Elapsed
+	}
+	if clicks := stats.Clicks(); clicks > 0 {
+		stats.Efficiency = float64(stats.Solved) / float64(clicks)
+	}
+	if seconds := stats.Elapsed.Seconds(); seconds > 0 {
+		stats.ThreeBVPerSecond = float64(stats.Solved) / seconds
+		stats.ClicksPerSecond = float64(stats.Clicks()) / seconds
+	}
+	return stats
+}
+
+// Returns the 3BV cleared so far: each opening with a revealed tile and each revealed number that does not border an opening.
+func (game *Game) solvedThreeBV() int {
+	labels := game.Board.OpeningLabels()
+	openings := map[int]bool{}
+	solved := 0
+	for y, row := range game.Revealed {
+		for x, value := range row {
+			if value < 0 || value == Exploded {
+				continue
+			}
+			if labels[y][x] > 0 {
+				openings[labels[y][x]] = true
+			} else if !game.Board.BordersOpening(y, x) {
+				solved++
+			}
+		}
+	}
+	return solved + len(openings)
+}
+
+// Contribution is one player's share of the clicks in a cooperative game.
+type Contribution struct {
+	Player string `json:"player"`
+	Clicks int    `json:"clicks"`
+	Useful int    `json:"useful"`
+	Wasted int    `json:"wasted"`
+	Flags  int    `json:"flags"`
+	// Cleared is the number of tiles revealed by the player's clicks.
+	Cleared int `json:"cleared"`
+}
+
+// Returns each player's contribution to a game, in the order they first moved.
+func (game *Game) Contributions() []Contribution {
+	contributions := []Contribution{}
+	indexes := map[string]int{}
+	for _, move := range game.Moves {
+		index, ok := indexes[move.Player]
+		if !ok {
+			index = len(contributions)
+			indexes[move.Player] = index
+			contributions = append(contributions, Contribution{Player: move.Player})
+		}
+		contribution := &contributions[index]
+		contribution.Clicks++
+		contribution.Cleared += move.Cleared
+		if move.Action == Flag {
+			contribution.Flags++
+		}
+		if move.Useful {
+			contribution.Useful++
+		} else {
+			contribution.Wasted++
+		}
+	}
+	return contributions
+}
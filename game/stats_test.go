@@ -0,0 +1,60 @@
+package game
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+func TestStats(test *testing.T) {
+	board, _ := generation.FromLayout([]string{
+		"*..",
+		"...",
+		"*..",
+	})
+	// 3BV is the opening on the right plus the 2 between the mines, which the opening does not reveal.
+	game := *NewGame(*board)
+	game.Play(Move{Coordinate: Coordinate{2, 1}, Action: Reveal, Elapsed: time.Second})
+	game.Play(Move{Coordinate: Coordinate{2, 1}, Action: Reveal, Elapsed: 2 * time.Second})
+	game.Play(Move{Coordinate: Coordinate{0, 0}, Action: Flag, Elapsed: 3 * time.Second})
+	game.Play(Move{Coordinate: Coordinate{0, 1}, Action: Reveal, Elapsed: 4 * time.Second})
+
+	stats := game.Stats()
+	if stats.ThreeBV != 2 || stats.Solved != 2 {
+		log.Printf("Expected 2 of 2 3BV solved. Actual: %+v", stats)
+		test.Fail()
+	}
+	if stats.Reveals != 3 || stats.Flags != 1 || stats.Useful != 3 || stats.Wasted != 1 {
+		log.Printf("Click counts were not as expected. Actual: %+v", stats)
+		test.Fail()
+	}
+	if stats.Efficiency != 0.5 || stats.ThreeBVPerSecond != 0.5 || stats.ClicksPerSecond != 1 {
+		log.Printf("Rates were not as expected. Actual: %+v", stats)
+		test.Fail()
+	}
+}
+
+func TestContributions(test *testing.T) {
+	board, _ := generation.FromLayout([]string{
+		"*..",
+		"...",
+		"*..",
+	})
+	game := *NewGame(*board)
+	game.Play(Move{Coordinate: Coordinate{2, 1}, Action: Reveal, Player: "alice"})
+	game.Play(Move{Coordinate: Coordinate{2, 1}, Action: Reveal, Player: "bob"})
+	game.Play(Move{Coordinate: Coordinate{0, 0}, Action: Flag, Player: "bob"})
+	game.Play(Move{Coordinate: Coordinate{0, 1}, Action: Reveal, Player: "alice"})
+
+	contributions := game.Contributions()
+	expected := []Contribution{
+		{Player: "alice", Clicks: 2, Useful: 2, Cleared: 7},
+		{Player: "bob", Clicks: 2, Useful: 1, Wasted: 1, Flags: 1},
+	}
+	if len(contributions) != 2 || contributions[0] != expected[0] || contributions[1] != expected[1] {
+		log.Printf("Contributions were not as expected. Actual: %+v", contributions)
+		test.Fail()
+	}
+}
@@ -3,12 +3,22 @@ package generation
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 )
 
 type Board struct {
-	Mines int
-	Field [][]int
-	Seed  int64
+	Mines    int
+	Field    [][]int
+	Seed     int64
+	Topology Topology
+	// Neighborhood is the tiles each number counts, nil for the eight surrounding it.
+	Neighborhood Neighborhood
+	// MinesPerTile is the most mines a tile can hold, zero for boards holding one mine per mine tile.
+	// Counts holds the number of mines in each tile of such boards, and is nil otherwise.
+	MinesPerTile int
+	Counts       [][]int
+	// Layers is the number of layers the rows are cut into on three dimensional boards, zero otherwise.
+	Layers int
 }
 
 // Returns the width and height of a board.
@@ -16,6 +26,11 @@ func (board Board) BoardSize() (int, int) {
 	return len(board.Field[0]), len(board.Field)
 }
 func NewBoard(mines int, width int, height int, seed int64) (*Board, error) {
+	return generate(mines, 1, width, height, seed)
+}
+
+// Places mines on a blank board from a seed, allowing up to perTile mines in a tile.
+func generate(mines int, perTile int, width int, height int, seed int64) (*Board, error) {
 	var inputValidation = func() error {
 		if mines < 0 {
 			return fmt.Errorf("mines value cannot be negative")
@@ -30,7 +45,10 @@ func NewBoard(mines int, width int, height int, seed int64) (*Board, error) {
 		return nil, inputErr
 	}
 
-	board := Board{mines, blankField(width, height), seed}
+	board := Board{Mines: mines, Field: blankField(width, height), Seed: seed}
+	if perTile > 1 {
+		board.MinesPerTile, board.Counts = perTile, blankField(width, height)
+	}
 	var genErr = board.generateMines()
 	valid, err := board.Validate()
 	if !valid {
@@ -38,6 +56,63 @@ func NewBoard(mines int, width int, height int, seed int64) (*Board, error) {
 	}
 	return &board, genErr
 }
+
+// Returns a Board built from a layout of rows, where '*' marks a mine and '.' marks a safe tile. A digit from 2 to
+// MaxMinesPerTile marks a tile holding that many mines, making a board that stacks mines.
+func FromLayout(layout []string) (*Board, error) {
+	if len(layout) == 0 || len(layout[0]) == 0 {
+		return nil, fmt.Errorf("layout must contain at least one row and one column")
+	}
+	width, height := len(layout[0]), len(layout)
+	board := Board{Field: blankField(width, height)}
+	if strings.ContainsAny(strings.Join(layout, ""), "234") {
+		board.MinesPerTile, board.Counts = MaxMinesPerTile, blankField(width, height)
+	}
+	for y, row := range layout {
+		if len(row) != width {
+			return nil, fmt.Errorf("layout rows must all be the same width. Row %d: Actual %d, Expected %d", y, len(row), width)
+		}
+		for x, tile := range row {
+			switch tile {
+			case '*':
+				board.Mines++
+				board.addMine(y, x)
+			case '.':
+			case '2', '3', '4': // Up to MaxMinesPerTile.
+				for count := int(tile - '0'); count > 0; count-- {
+					board.Mines++
+					board.addMine(y, x)
+				}
+			default:
+				return nil, fmt.Errorf("layout contains an unknown tile %q at %d_%d", tile, y, x)
+			}
+		}
+	}
+	valid, err := board.Validate()
+	if !valid {
+		return &board, fmt.Errorf("Board layout is invalid: %s", err)
+	}
+	return &board, nil
+}
+
+// Returns the layout of a board, where '*' marks a mine and '.' marks a safe tile.
+func (board Board) Layout() []string {
+	layout := make([]string, len(board.Field))
+	for y, row := range board.Field {
+		tiles := make([]byte, len(row))
+		for x, value := range row {
+			tiles[x] = '.'
+			if count := board.MinesAt(y, x); count > 1 {
+				tiles[x] = byte('0' + count)
+			} else if value == -9 {
+				tiles[x] = '*'
+			}
+		}
+		layout[y] = string(tiles)
+	}
+	return layout
+}
+
 func blankField(width int, height int) [][]int {
 	var arr = make([][]int, height)
 	for i := 0; i < height; i++ {
@@ -61,7 +136,7 @@ func (board Board) generateMines() error {
 	width, height := board.BoardSize()
 
 	var pregenTests = func() error {
-		if board.Mines > width*height {
+		if board.Mines > width*height*board.perTile() {
 			return fmt.Errorf("board size specified cannot hold the number mines provided")
 		}
 		return nil // No error detected
@@ -70,33 +145,31 @@ func (board Board) generateMines() error {
 		return testResult
 	}
 
-	// Traverses all adjacent tiles in a 1 tile radius
-	var populateHints = func(y int, x int) {
-		for yOffset := -1; yOffset <= 1; yOffset++ {
-			for xOffset := -1; xOffset <= 1; xOffset++ {
-				if yAdjusted, xAdjusted := y+yOffset, x+xOffset; isValidTile(board, yAdjusted, xAdjusted) {
-					board.Field[yAdjusted][xAdjusted] += 1
-				}
-			}
-		}
-	}
-
 	var random = rand.New(rand.NewSource((board.Seed)))
 	// Iterates until n mines have been successfully placed.
 	for count := 0; count < board.Mines; {
 		var x = random.Intn(width)
 		var y = random.Intn(height)
-		if board.Field[y][x] == -9 {
+		if board.MinesAt(y, x) >= board.perTile() {
 			continue
-			// Does not count to the progress of mines on the occasion that a mine already exists in a location.
+			// Does not count to the progress of mines on the occasion that a location already holds all the mines it can.
 		}
 		count++
-		board.Field[y][x] = -9
-		populateHints(y, x)
+		board.addMine(y, x)
 	}
 	return nil
 }
 
+// Places a mine at position (x, y) and increments the hints of all its neighbors.
+func (board Board) placeMine(y int, x int) {
+	board.Field[y][x] = -9
+	for _, neighbor := range board.Neighbors(y, x) {
+		if yAdjusted, xAdjusted := neighbor[0], neighbor[1]; isValidTile(board, yAdjusted, xAdjusted) {
+			board.Field[yAdjusted][xAdjusted] += 1
+		}
+	}
+}
+
 // Returns true if a Board is considered valid, false otherwise.
 func (board Board) Validate() (bool, error) {
 	width, height := board.BoardSize()
@@ -105,9 +178,7 @@ func (board Board) Validate() (bool, error) {
 		var actual = 0
 		for y := 0; y < height; y++ {
 			for x := 0; x < width; x++ {
-				if board.Field[y][x] == -9 {
-					actual++
-				}
+				actual += board.MinesAt(y, x)
 			}
 		}
 		return actual
@@ -120,14 +191,8 @@ func (board Board) Validate() (bool, error) {
 				// Returns -9 for mines to ensure correct behavior.
 			}
 			var minesFound = 0
-			for yOffset := -1; yOffset <= 1; yOffset++ {
-				for xOffset := -1; xOffset <= 1; xOffset++ {
-					if yAdjusted, xAdjusted := y+yOffset, x+xOffset; board.IsInRange(yAdjusted, xAdjusted) {
-						if board.Field[yAdjusted][xAdjusted] == -9 {
-							minesFound++
-						}
-					}
-				}
+			for _, neighbor := range board.Neighbors(y, x) {
+				minesFound += board.MinesAt(neighbor[0], neighbor[1])
 			}
 			return minesFound
 		}
@@ -148,5 +213,13 @@ func (board Board) Validate() (bool, error) {
 	} else if actual < board.Mines {
 		return false, fmt.Errorf("too few mines placed Actual %d, Expected %d", actual, board.Mines)
 	}
+	// On boards that stack mines, only mine tiles hold mines and none holds more than it can.
+	for y, row := range board.Counts {
+		for x, count := range row {
+			if (count > 0) != (board.Field[y][x] == -9) || count > board.perTile() {
+				return false, fmt.Errorf("tile %d_%d holds %d mines", y, x, count)
+			}
+		}
+	}
 	return hintVeracity()
 }
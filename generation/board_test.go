@@ -55,3 +55,55 @@ func TestGeneration_Valid(test *testing.T) {
 		test.Fail()
 	}
 }
+
+func TestFromLayout_Valid(test *testing.T) {
+	layout := []string{
+		"*..",
+		"...",
+		"..*",
+	}
+	board, err := FromLayout(layout)
+	if err != nil {
+		log.Printf("Error detected: %v\n", err.Error())
+		test.FailNow()
+	}
+	if board.Mines != 2 {
+		log.Printf("Expected 2 mines from layout. Actual: %d", board.Mines)
+		test.Fail()
+	}
+	if board.Field[1][1] != 2 {
+		log.Printf("Expected center hint to be 2. Actual: %d", board.Field[1][1])
+		test.Fail()
+	}
+	for y, row := range board.Layout() {
+		if row != layout[y] {
+			log.Printf("Layout did not round trip. Actual: %v", board.Layout())
+			test.Fail()
+		}
+	}
+}
+
+func TestFromLayout_Ragged(test *testing.T) {
+	if _, err := FromLayout([]string{"*..", ".."}); err == nil {
+		log.Printf("Expected Generation#FromLayout to produce error for ragged rows.")
+		test.Fail()
+	}
+}
+
+func TestThreeBV(test *testing.T) {
+	board, _ := FromLayout([]string{
+		"....",
+		"....",
+		"...*",
+		"*...",
+	})
+	// One opening reveals everything except the three numbers along the bottom row.
+	if threeBV := board.ThreeBV(); threeBV != 4 {
+		log.Printf("Expected 3BV of 4. Actual: %d", threeBV)
+		test.Fail()
+	}
+	if openings := board.Openings(); openings != 1 {
+		log.Printf("Expected 1 opening. Actual: %d", openings)
+		test.Fail()
+	}
+}
@@ -0,0 +1,60 @@
+package generation
+
+import "fmt"
+
+// MaxLayers is the most layers a three dimensional board can have.
+const MaxLayers = 8
+
+// Returns a copy of a board with the same mines cut into layers stacked on top of each other, with its hints
+// counted again. The rows of the board are shared out between the layers in order, so a board of height 24 in
+// 3 layers is a cube of 3 boards of height 8, and each tile neighbors the up to 26 tiles around it in three
+// dimensions. Only flat boards with the standard neighborhood can be layered.
+func (board Board) InLayers(layers int) (*Board, error) {
+	_, height := board.BoardSize()
+	if layers < 1 || layers > MaxLayers {
+		return nil, fmt.Errorf("a board can have between 1 and %d layers. Actual: %d", MaxLayers, layers)
+	}
+	if height%layers != 0 {
+		return nil, fmt.Errorf("a board of height %d cannot be cut into %d layers", height, layers)
+	}
+	if layers > 1 && (board.Topology != Plane || board.Neighborhood != nil) {
+		return nil, fmt.Errorf("only flat boards with the standard neighborhood can have layers")
+	}
+	if layers == 1 {
+		layers = 0
+	}
+	return board.reshaped(Grid{Topology: board.Topology, Neighborhood: board.Neighborhood, Layers: layers}), nil
+}
+
+// Returns the number of rows in each layer of a grid, which is its height on boards without layers.
+func (grid Grid) LayerHeight() int {
+	return grid.Height / max(1, grid.Layers)
+}
+
+// Returns the layer a row of a grid is in and its row within that layer.
+func (grid Grid) Layer(y int) (int, int) {
+	return y / grid.LayerHeight(), y % grid.LayerHeight()
+}
+
+// Returns the positions of the tiles touching (x, y) in its own layer and the layers above and below it.
+func (grid Grid) layerNeighbors(y int, x int) [][2]int {
+	rows := grid.LayerHeight()
+	layer, row := grid.Layer(y)
+	neighbors := make([][2]int, 0, 26)
+	for layerOffset := -1; layerOffset <= 1; layerOffset++ {
+		for rowOffset := -1; rowOffset <= 1; rowOffset++ {
+			for xOffset := -1; xOffset <= 1; xOffset++ {
+				adjustedLayer, adjustedRow, xAdjusted := layer+layerOffset, row+rowOffset, x+xOffset
+				if layerOffset == 0 && rowOffset == 0 && xOffset == 0 {
+					continue
+				}
+				if adjustedLayer < 0 || adjustedLayer >= grid.Layers || adjustedRow < 0 || adjustedRow >= rows ||
+					xAdjusted < 0 || xAdjusted >= grid.Width {
+					continue
+				}
+				neighbors = append(neighbors, [2]int{adjustedLayer*rows + adjustedRow, xAdjusted})
+			}
+		}
+	}
+	return neighbors
+}
@@ -0,0 +1,74 @@
+package generation
+
+import (
+	"log"
+	"testing"
+)
+
+func TestLayerNeighbors(test *testing.T) {
+	cube := Grid{3, 9, Plane, nil, 3}
+	if neighbors := cube.Neighbors(4, 1); len(neighbors) != 26 {
+		log.Printf("Expected the middle of a cube to have 26 neighbors. Actual: %v", neighbors)
+		test.Fail()
+	}
+	if neighbors := cube.Neighbors(0, 0); len(neighbors) != 7 {
+		log.Printf("Expected a corner of a cube to have 7 neighbors. Actual: %v", neighbors)
+		test.Fail()
+	}
+	// The last row of the first layer sits above the last row of the second, not next to its first row.
+	for _, neighbor := range cube.Neighbors(2, 1) {
+		if layer, row := cube.Layer(neighbor[0]); layer > 1 || row < 1 {
+			log.Printf("Expected the last row of a layer to touch only the last rows around it. Actual: %v", neighbor)
+			test.Fail()
+		}
+	}
+	if layer, row := cube.Layer(5); layer != 1 || row != 2 {
+		log.Printf("Expected row 5 to be row 2 of layer 1. Actual: row %d of layer %d", row, layer)
+		test.Fail()
+	}
+}
+
+func TestInLayers(test *testing.T) {
+	board, err := FromLayout([]string{
+		"...",
+		".*.",
+		"...",
+		"...",
+		"...",
+		"...",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	cube, err := board.InLayers(2)
+	if err != nil {
+		log.Printf("Error cutting board into layers: %s", err)
+		test.FailNow()
+	}
+	if valid, err := cube.Validate(); !valid {
+		log.Printf("Expected the layered board to be valid. Error: %s", err)
+		test.Fail()
+	}
+	// The tile directly below the mine, and the tiles around that, count it. On the flat board they are too far.
+	if cube.Field[4][1] != 1 || cube.Field[3][0] != 1 || cube.Field[5][2] != 1 || board.Field[4][1] != 0 {
+		log.Printf("Expected the second layer to count the mine above it. Actual: %v", cube.Field)
+		test.Fail()
+	}
+	if cube.Field[3][1] != 1 || board.Field[3][1] != 0 {
+		log.Printf("Expected the first row of the second layer to sit below the first. Actual: %v", cube.Field)
+		test.Fail()
+	}
+	if _, err := board.InLayers(4); err == nil {
+		log.Printf("Expected a height of 6 not to be cut into 4 layers.")
+		test.Fail()
+	}
+	if _, err := board.OnTopology(Torus).InLayers(2); err == nil {
+		log.Printf("Expected a torus not to be cut into layers.")
+		test.Fail()
+	}
+	if _, err := cube.WithNeighborhood(Neighborhoods["knight"]); err == nil {
+		log.Printf("Expected a layered board not to take a neighborhood.")
+		test.Fail()
+	}
+}
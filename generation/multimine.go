@@ -0,0 +1,40 @@
+package generation
+
+import "fmt"
+
+// MaxMinesPerTile is the most mines a tile of a board that stacks mines can hold. No number can exceed this times
+// the most neighbors a tile can have.
+const MaxMinesPerTile = 4
+
+// Returns a Board whose tiles each hold up to perTile mines, placed from a seed. Numbers count every mine in their
+// neighborhood, so a tile next to a stack of two shows 2. A perTile of 1 generates the same board as NewBoard.
+func NewMultiMineBoard(mines int, perTile int, width int, height int, seed int64) (*Board, error) {
+	if perTile < 1 || perTile > MaxMinesPerTile {
+		return nil, fmt.Errorf("a tile can hold between 1 and %d mines. Actual: %d", MaxMinesPerTile, perTile)
+	}
+	return generate(mines, perTile, width, height, seed)
+}
+
+// Returns the number of mines a tile holds.
+func (board Board) MinesAt(y int, x int) int {
+	if board.Counts != nil {
+		return board.Counts[y][x]
+	}
+	if board.Field[y][x] == -9 {
+		return 1
+	}
+	return 0
+}
+
+// Returns the most mines a tile of a board can hold.
+func (board Board) perTile() int {
+	return max(1, board.MinesPerTile)
+}
+
+// Adds a mine to a tile, counting it on boards that stack mines.
+func (board Board) addMine(y int, x int) {
+	if board.Counts != nil {
+		board.Counts[y][x]++
+	}
+	board.placeMine(y, x)
+}
@@ -0,0 +1,85 @@
+package generation
+
+import (
+	"log"
+	"reflect"
+	"testing"
+)
+
+func TestNewMultiMineBoard(test *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		board, err := NewMultiMineBoard(40, 3, 8, 8, seed)
+		if err != nil {
+			log.Printf("Error creating board with seed %d: %s", seed, err)
+			test.FailNow()
+		}
+		for y, row := range board.Counts {
+			for x, count := range row {
+				if count > 3 {
+					log.Printf("Expected at most 3 mines in a tile. Actual: %d at %d_%d", count, y, x)
+					test.Fail()
+				}
+			}
+		}
+	}
+	// A tile surrounded by full stacks counts every mine in them.
+	board, err := FromLayout([]string{"444", "4.4", "444"})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	if board.Field[1][1] != 32 {
+		log.Printf("Expected the middle tile to count 32 mines. Actual: %v", board.Field)
+		test.Fail()
+	}
+	if _, err := NewMultiMineBoard(37, 4, 3, 3, 1); err == nil {
+		log.Printf("Expected more mines than the tiles can hold to be rejected.")
+		test.Fail()
+	}
+	if _, err := NewMultiMineBoard(10, MaxMinesPerTile+1, 9, 9, 1); err == nil {
+		log.Printf("Expected more than %d mines per tile to be rejected.", MaxMinesPerTile)
+		test.Fail()
+	}
+}
+
+func TestOneMinePerTileMatchesNewBoard(test *testing.T) {
+	classic, err := NewBoard(10, 9, 9, 42)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	stacked, err := NewMultiMineBoard(10, 1, 9, 9, 42)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	if !reflect.DeepEqual(classic, stacked) {
+		log.Printf("Expected one mine per tile to generate the same board as NewBoard. Actual: %v, Expected: %v", stacked, classic)
+		test.Fail()
+	}
+}
+
+func TestStackedLayout(test *testing.T) {
+	layout := []string{
+		"3..",
+		"...",
+		"..*",
+	}
+	board, err := FromLayout(layout)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	if board.Mines != 4 || board.MinesAt(0, 0) != 3 || board.Field[1][1] != 4 || board.Field[0][1] != 3 {
+		log.Printf("Expected a stack of 3 and a single mine. Actual: %d mines, %v", board.Mines, board.Field)
+		test.Fail()
+	}
+	if actual := board.Layout(); !reflect.DeepEqual(actual, layout) {
+		log.Printf("Expected the layout to round trip. Actual: %v", actual)
+		test.Fail()
+	}
+	if _, err := FromLayout([]string{"5."}); err == nil {
+		log.Printf("Expected a stack of more than %d mines to be rejected.", MaxMinesPerTile)
+		test.Fail()
+	}
+}
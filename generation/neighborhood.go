@@ -0,0 +1,107 @@
+package generation
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxOffset bounds how far a neighborhood can reach from its tile.
+const maxOffset = 4
+
+// MaxNeighbors is the most offsets a neighborhood can have, as many as the 5 by 5 square around a tile.
+const MaxNeighbors = 24
+
+// Offset is the position of a neighbor relative to its tile.
+type Offset struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Neighborhood is the set of tiles a number counts the mines of, as offsets from the number. The nil
+// Neighborhood is the standard one of the eight surrounding tiles.
+//
+// A neighborhood is symmetric, so a tile is a neighbor of each of its neighbors, and has at most MaxNeighbors
+// offsets. Its hints can go above 8.
+type Neighborhood []Offset
+
+// Neighborhoods are the named neighborhoods boards can be played with.
+var Neighborhoods = map[string]Neighborhood{
+	"standard":   nil,
+	"knight":     {{1, -2}, {2, -1}, {2, 1}, {1, 2}, {-1, 2}, {-2, 1}, {-2, -1}, {-1, -2}},
+	"orthogonal": {{0, -1}, {1, 0}, {0, 1}, {-1, 0}},
+	"ring": {{-2, -2}, {-1, -2}, {0, -2}, {1, -2}, {2, -2}, {2, -1}, {2, 0}, {2, 1}, {2, 2}, {1, 2}, {0, 2}, {-1, 2},
+		{-2, 2}, {-2, 1}, {-2, 0}, {-2, -1}},
+}
+
+// NeighborhoodNames are the names of the Neighborhoods, in the order they are offered.
+var NeighborhoodNames = []string{"standard", "knight", "orthogonal", "ring"}
+
+// Returns the neighborhood with a name, or one written as offsets such as "1,2 2,1 -1,-2 -2,-1", each an x and
+// a y. An empty name is the standard neighborhood.
+func ParseNeighborhood(text string) (Neighborhood, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	if neighborhood, ok := Neighborhoods[text]; ok {
+		return neighborhood, nil
+	}
+	neighborhood := Neighborhood{}
+	for _, pair := range strings.Fields(text) {
+		x, y, found := strings.Cut(pair, ",")
+		xOffset, xErr := strconv.Atoi(x)
+		yOffset, yErr := strconv.Atoi(y)
+		if !found || xErr != nil || yErr != nil {
+			return nil, fmt.Errorf("unknown neighborhood %q: offsets are written as x,y", text)
+		}
+		neighborhood = append(neighborhood, Offset{xOffset, yOffset})
+	}
+	return neighborhood, neighborhood.Validate()
+}
+
+// Returns an error if a neighborhood has too many or too distant offsets, or counts a tile differently from its
+// neighbors.
+func (neighborhood Neighborhood) Validate() error {
+	if neighborhood == nil {
+		return nil
+	}
+	if len(neighborhood) == 0 || len(neighborhood) > MaxNeighbors {
+		return fmt.Errorf("a neighborhood must have between 1 and %d offsets. Actual: %d", MaxNeighbors, len(neighborhood))
+	}
+	for index, offset := range neighborhood {
+		if offset == (Offset{}) {
+			return fmt.Errorf("a neighborhood cannot include its own tile")
+		}
+		if offset.X < -maxOffset || offset.X > maxOffset || offset.Y < -maxOffset || offset.Y > maxOffset {
+			return fmt.Errorf("offset %d,%d is more than %d tiles away", offset.X, offset.Y, maxOffset)
+		}
+		if slices.Contains(neighborhood[:index], offset) {
+			return fmt.Errorf("offset %d,%d is repeated", offset.X, offset.Y)
+		}
+		if !slices.Contains(neighborhood, Offset{-offset.X, -offset.Y}) {
+			return fmt.Errorf("offset %d,%d has no opposite %d,%d", offset.X, offset.Y, -offset.X, -offset.Y)
+		}
+	}
+	return nil
+}
+
+// Returns the name of a neighborhood, or "custom" if it is not one of the Neighborhoods.
+func (neighborhood Neighborhood) Name() string {
+	for _, name := range NeighborhoodNames {
+		if slices.Equal(Neighborhoods[name], neighborhood) {
+			return name
+		}
+	}
+	return "custom"
+}
+
+// Returns the offsets of a neighborhood in the form ParseNeighborhood reads.
+func (neighborhood Neighborhood) String() string {
+	pairs := make([]string, len(neighborhood))
+	for index, offset := range neighborhood {
+		pairs[index] = fmt.Sprintf("%d,%d", offset.X, offset.Y)
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,96 @@
+package generation
+
+import (
+	"fmt"
+	"log"
+	"testing"
+)
+
+func TestParseNeighborhood(test *testing.T) {
+	if neighborhood, err := ParseNeighborhood("knight"); err != nil || len(neighborhood) != 8 || neighborhood.Name() != "knight" {
+		log.Printf("Expected the knight neighborhood. Actual: %v %v", neighborhood, err)
+		test.Fail()
+	}
+	if neighborhood, err := ParseNeighborhood(""); err != nil || neighborhood != nil || neighborhood.Name() != "standard" {
+		log.Printf("Expected an empty name to be the standard neighborhood. Actual: %v %v", neighborhood, err)
+		test.Fail()
+	}
+	custom, err := ParseNeighborhood("0,-2 0,2")
+	if err != nil || custom.Name() != "custom" || custom.String() != "0,-2 0,2" {
+		log.Printf("Expected custom offsets to parse. Actual: %v %v", custom, err)
+		test.Fail()
+	}
+	// The 5 by 5 square around a tile and two more offsets is one more than MaxNeighbors.
+	tooMany := "3,0 -3,0"
+	for y := -2; y <= 2; y++ {
+		for x := -2; x <= 2; x++ {
+			if x != 0 || y != 0 {
+				tooMany += fmt.Sprintf(" %d,%d", x, y)
+			}
+		}
+	}
+	for _, invalid := range []string{"0,1", "0,0", "1,1 1,1 -1,-1", "9,0 -9,0", "1;2", tooMany} {
+		if _, err := ParseNeighborhood(invalid); err == nil {
+			log.Printf("Expected %q to be rejected.", invalid)
+			test.Fail()
+		}
+	}
+}
+
+func TestRingCountsSixteenTiles(test *testing.T) {
+	board, err := FromLayout([]string{
+		"*****",
+		"*...*",
+		"*...*",
+		"*...*",
+		"*****",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	ring, err := board.WithNeighborhood(Neighborhoods["ring"])
+	if err != nil {
+		log.Printf("Error applying neighborhood: %s", err)
+		test.FailNow()
+	}
+	if len(Neighborhoods["ring"]) != 16 || ring.Field[2][2] != 16 || ring.Field[1][2] != 8 {
+		log.Printf("Expected the middle tile to count every mine two tiles away. Actual: %v", ring.Field)
+		test.Fail()
+	}
+}
+
+func TestWithNeighborhood(test *testing.T) {
+	board, err := FromLayout([]string{
+		".....",
+		".....",
+		"..*..",
+		".....",
+		".....",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	knight, err := board.WithNeighborhood(Neighborhoods["knight"])
+	if err != nil {
+		log.Printf("Error applying neighborhood: %s", err)
+		test.FailNow()
+	}
+	if valid, err := knight.Validate(); !valid {
+		log.Printf("Expected the knight board to be valid. Error: %s", err)
+		test.Fail()
+	}
+	if knight.Field[0][1] != 1 || knight.Field[1][2] != 0 || knight.Field[1][1] != 0 {
+		log.Printf("Expected only tiles a knight's move from the mine to count it. Actual: %v", knight.Field)
+		test.Fail()
+	}
+	if _, err := board.OnTopology(Hex).WithNeighborhood(Neighborhoods["knight"]); err == nil {
+		log.Printf("Expected a hex board to refuse a custom neighborhood.")
+		test.Fail()
+	}
+	if torus := knight.OnTopology(Torus); torus.Neighborhood == nil {
+		log.Printf("Expected a change of topology to keep the neighborhood.")
+		test.Fail()
+	}
+}
@@ -0,0 +1,68 @@
+package generation
+
+// Returns a label for every tile: the 1-based index of the opening a blank tile belongs to, or 0 otherwise.
+// An opening is a connected region of blank tiles.
+func (board Board) OpeningLabels() [][]int {
+	width, height := board.BoardSize()
+	labels := make([][]int, height)
+	for y := range labels {
+		labels[y] = make([]int, width)
+	}
+	openings := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if board.Field[y][x] != 0 || labels[y][x] != 0 {
+				continue
+			}
+			openings++
+			labels[y][x] = openings
+			queue := [][2]int{{y, x}}
+			for len(queue) > 0 {
+				current := queue[0]
+				queue = queue[1:]
+				for _, neighbor := range board.Neighbors(current[0], current[1]) {
+					if board.Field[neighbor[0]][neighbor[1]] == 0 && labels[neighbor[0]][neighbor[1]] == 0 {
+						labels[neighbor[0]][neighbor[1]] = openings
+						queue = append(queue, neighbor)
+					}
+				}
+			}
+		}
+	}
+	return labels
+}
+
+// Returns the number of openings on a board.
+func (board Board) Openings() int {
+	openings := 0
+	for _, row := range board.OpeningLabels() {
+		for _, label := range row {
+			openings = max(openings, label)
+		}
+	}
+	return openings
+}
+
+// Returns true if a tile borders a blank tile, meaning it is revealed by clicking an opening.
+func (board Board) BordersOpening(y int, x int) bool {
+	for _, neighbor := range board.Neighbors(y, x) {
+		if board.Field[neighbor[0]][neighbor[1]] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the 3BV of a board, the minimum number of clicks needed to clear it without flagging:
+// one for each opening and one for each number that does not border an opening.
+func (board Board) ThreeBV() int {
+	threeBV := board.Openings()
+	for y, row := range board.Field {
+		for x, value := range row {
+			if value > 0 && !board.BordersOpening(y, x) {
+				threeBV++
+			}
+		}
+	}
+	return threeBV
+}
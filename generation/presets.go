@@ -0,0 +1,18 @@
+package generation
+
+// Preset is a standard board size offered when generating a new game.
+type Preset struct {
+	Mines  int
+	Width  int
+	Height int
+}
+
+// Presets are the standard difficulties by name. Only games on these boards are ranked.
+var Presets = map[string]Preset{
+	"beginner":     {Mines: 10, Width: 8, Height: 8},
+	"intermediate": {Mines: 40, Width: 16, Height: 16},
+	"expert":       {Mines: 99, Width: 30, Height: 16},
+}
+
+// PresetNames lists the presets from easiest to hardest.
+var PresetNames = []string{"beginner", "intermediate", "expert"}
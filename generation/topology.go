@@ -0,0 +1,154 @@
+package generation
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Topology is the shape of a board's surface, deciding which tiles neighbor each other.
+type Topology string
+
+const (
+	// Plane is a flat board whose edges are walls. It is the zero value, so boards without a topology are planes.
+	Plane Topology = ""
+	// Torus is a board whose edges wrap: the left column neighbors the right and the top row the bottom.
+	Torus Topology = "torus"
+	// Hex is a flat board of hexagons with six neighbors each. Rows are stored as usual, with every odd row
+	// drawn half a tile to the right of the rows around it.
+	Hex Topology = "hex"
+)
+
+// TopologyNames are the names topologies are chosen by.
+var TopologyNames = []string{"plane", "torus", "hex"}
+
+// hexOffsets are the (y, x) offsets of the six neighbors of a hexagon in an even row, then in an odd row.
+var hexOffsets = [2][6][2]int{
+	{{-1, -1}, {-1, 0}, {0, -1}, {0, 1}, {1, -1}, {1, 0}},
+	{{-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, 0}, {1, 1}},
+}
+
+// Returns the Topology with a name. An empty name is a Plane.
+func ParseTopology(name string) (Topology, error) {
+	switch name {
+	case "", "plane":
+		return Plane, nil
+	case "torus":
+		return Torus, nil
+	case "hex":
+		return Hex, nil
+	}
+	return Plane, fmt.Errorf("unknown topology %q", name)
+}
+
+func (topology Topology) String() string {
+	if topology == Plane {
+		return "plane"
+	}
+	return string(topology)
+}
+
+// Grid is the size and rules of a board: everything that decides which tiles neighbor each other.
+type Grid struct {
+	Width        int
+	Height       int
+	Topology     Topology
+	Neighborhood Neighborhood
+	// Layers is the number of layers the rows are cut into on three dimensional boards, zero otherwise.
+	Layers int
+}
+
+// standardNeighborhood is the eight tiles surrounding a tile, used by boards without a Neighborhood.
+var standardNeighborhood = Neighborhood{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+// Returns the positions, as (y, x) pairs, of the tiles neighboring (x, y). Each neighbor is listed once and the
+// tile itself never is, even on boards small enough for a torus to wrap onto itself. Hex boards always use
+// the six touching hexagons, as neighborhoods are written in square offsets, and layered boards the tiles
+// around a tile in three dimensions.
+func (grid Grid) Neighbors(y int, x int) [][2]int {
+	if grid.Layers > 1 {
+		return grid.layerNeighbors(y, x)
+	}
+	neighbors := make([][2]int, 0, 8)
+	if grid.Topology == Hex {
+		for _, offset := range hexOffsets[y&1] {
+			if yAdjusted, xAdjusted := y+offset[0], x+offset[1]; grid.inRange(yAdjusted, xAdjusted) {
+				neighbors = append(neighbors, [2]int{yAdjusted, xAdjusted})
+			}
+		}
+		return neighbors
+	}
+	offsets := grid.Neighborhood
+	if offsets == nil {
+		offsets = standardNeighborhood
+	}
+	for _, offset := range offsets {
+		yAdjusted, xAdjusted := y+offset.Y, x+offset.X
+		if grid.Topology == Torus {
+			yAdjusted, xAdjusted = wrap(yAdjusted, grid.Height), wrap(xAdjusted, grid.Width)
+		} else if !grid.inRange(yAdjusted, xAdjusted) {
+			continue
+		}
+		neighbor := [2]int{yAdjusted, xAdjusted}
+		if neighbor != [2]int{y, x} && !slices.Contains(neighbors, neighbor) {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	return neighbors
+}
+
+func (grid Grid) inRange(y int, x int) bool {
+	return x >= 0 && x < grid.Width && y >= 0 && y < grid.Height
+}
+
+// Returns a position wrapped onto a row or column of a length.
+func wrap(position int, length int) int {
+	return ((position % length) + length) % length
+}
+
+// Returns the size and rules of a board.
+func (board Board) Grid() Grid {
+	width, height := board.BoardSize()
+	return Grid{width, height, board.Topology, board.Neighborhood, board.Layers}
+}
+
+// Returns the positions of the tiles neighboring (x, y) on a board.
+func (board Board) Neighbors(y int, x int) [][2]int {
+	return board.Grid().Neighbors(y, x)
+}
+
+// Returns a copy of a board with the same mines on another topology, with its hints counted again.
+func (board Board) OnTopology(topology Topology) *Board {
+	return board.reshaped(Grid{Topology: topology, Neighborhood: board.Neighborhood, Layers: board.Layers})
+}
+
+// Returns a copy of a board with the same mines whose numbers count a neighborhood, with its hints counted again.
+// Hex boards cannot use neighborhoods, which are written in square offsets, and neither can layered boards.
+func (board Board) WithNeighborhood(neighborhood Neighborhood) (*Board, error) {
+	if err := neighborhood.Validate(); err != nil {
+		return nil, err
+	}
+	if neighborhood != nil && board.Topology == Hex {
+		return nil, fmt.Errorf("hex boards cannot use a custom neighborhood")
+	}
+	if neighborhood != nil && board.Layers > 1 {
+		return nil, fmt.Errorf("layered boards cannot use a custom neighborhood")
+	}
+	return board.reshaped(Grid{Topology: board.Topology, Neighborhood: neighborhood, Layers: board.Layers}), nil
+}
+
+// Returns a copy of a board with the same mines following the rules of a grid, whose size is ignored.
+func (board Board) reshaped(rules Grid) *Board {
+	width, height := board.BoardSize()
+	moved := Board{board.Mines, blankField(width, height), board.Seed, rules.Topology, rules.Neighborhood, board.MinesPerTile, nil, rules.Layers}
+	if board.Counts != nil {
+		moved.Counts = blankField(width, height)
+	}
+	for y, row := range board.Field {
+		for x := range row {
+			for count := board.MinesAt(y, x); count > 0; count-- {
+				moved.addMine(y, x)
+			}
+		}
+	}
+	return &moved
+}
@@ -0,0 +1,89 @@
+package generation
+
+import (
+	"log"
+	"testing"
+)
+
+func TestTorusNeighbors(test *testing.T) {
+	if neighbors := (Grid{5, 4, Torus, nil, 0}).Neighbors(0, 0); len(neighbors) != 8 {
+		log.Printf("Expected a corner of a torus to have 8 neighbors. Actual: %v", neighbors)
+		test.Fail()
+	}
+	if neighbors := (Grid{5, 4, Plane, nil, 0}).Neighbors(0, 0); len(neighbors) != 3 {
+		log.Printf("Expected a corner of a plane to have 3 neighbors. Actual: %v", neighbors)
+		test.Fail()
+	}
+	// On a board two tiles wide, wrapping left and right reaches the same column, which is only counted once.
+	if neighbors := (Grid{2, 2, Torus, nil, 0}).Neighbors(0, 0); len(neighbors) != 3 {
+		log.Printf("Expected a 2x2 torus to count each neighbor once. Actual: %v", neighbors)
+		test.Fail()
+	}
+}
+
+func TestOnTopology(test *testing.T) {
+	board, err := FromLayout([]string{
+		"*....",
+		".....",
+		".....",
+		".....",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	torus := board.OnTopology(Torus)
+	if valid, err := torus.Validate(); !valid {
+		log.Printf("Expected the torus to be valid. Error: %s", err)
+		test.Fail()
+	}
+	if torus.Field[3][4] != 1 || board.Field[3][4] != 0 || torus.Field[0][0] != -9 {
+		log.Printf("Expected the far corner to touch the mine only on the torus. Actual: %v", torus.Field)
+		test.Fail()
+	}
+	if topology, err := ParseTopology("torus"); err != nil || topology != Torus {
+		log.Printf("Expected torus to parse. Actual: %q %v", topology, err)
+		test.Fail()
+	}
+	if _, err := ParseTopology("sphere"); err == nil {
+		log.Printf("Expected an unknown topology to be rejected.")
+		test.Fail()
+	}
+}
+
+func TestHexNeighbors(test *testing.T) {
+	hex := Grid{5, 5, Hex, nil, 0}
+	if neighbors := hex.Neighbors(2, 2); len(neighbors) != 6 {
+		log.Printf("Expected a hexagon to have 6 neighbors. Actual: %v", neighbors)
+		test.Fail()
+	}
+	// Even rows reach up and left, odd rows up and right.
+	even, odd := hex.Neighbors(2, 2), hex.Neighbors(1, 2)
+	if even[0] != [2]int{1, 1} || even[1] != [2]int{1, 2} || odd[0] != [2]int{0, 2} || odd[1] != [2]int{0, 3} {
+		log.Printf("Neighbors did not follow the row offsets. Even: %v Odd: %v", even, odd)
+		test.Fail()
+	}
+	if neighbors := hex.Neighbors(0, 0); len(neighbors) != 2 {
+		log.Printf("Expected the first corner of a hex board to have 2 neighbors. Actual: %v", neighbors)
+		test.Fail()
+	}
+
+	board, err := NewBoard(20, 10, 10, 3)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	hexBoard := board.OnTopology(Hex)
+	if valid, err := hexBoard.Validate(); !valid {
+		log.Printf("Expected the hex board to be valid. Error: %s", err)
+		test.Fail()
+	}
+	for _, row := range hexBoard.Field {
+		for _, value := range row {
+			if value > 6 {
+				log.Printf("Expected no hint above 6 on a hex board. Actual: %v", hexBoard.Field)
+				test.FailNow()
+			}
+		}
+	}
+}
@@ -3,6 +3,11 @@ module github.com/deadly990/gominesweeper
 go 1.23.2
 
 require (
-	github.com/go-chi/chi/v5 v5.1.0 // indirect
-	github.com/mattn/go-sqlite3 v1.14.24 // indirect
+	github.com/go-chi/chi/v5 v5.1.0
+	github.com/mattn/go-sqlite3 v1.14.24
+	golang.org/x/crypto v0.31.0
+	golang.org/x/net v0.33.0
+	golang.org/x/term v0.27.0
 )
+
+require golang.org/x/sys v0.28.0 // indirect
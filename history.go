@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+)
+
+// historyPageSize is the number of games listed per page of history.
+const historyPageSize = 50
+
+func historyHandler(w http.ResponseWriter, req *http.Request) {
+	player := playerName(w, req)
+	page := historyPage(req)
+	stats, records, err := loadHistory(player, page)
+	if err != nil {
+		log.Println("loadHistory:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	historyData := view.FromHistory(stats, records, page, len(records) == historyPageSize)
+	executeTemplate(w, "history.html", historyData)
+}
+
+func apiHistoryHandler(w http.ResponseWriter, req *http.Request) {
+	player := playerName(w, req)
+	stats, records, err := loadHistory(player, historyPage(req))
+	if err != nil {
+		log.Println("loadHistory:", err)
+		writeJSONError(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, view.HistoryJSON{Player: player, Stats: stats, Games: records})
+}
+
+// Returns the player's statistics and a page of their games.
+func loadHistory(player string, page int) ([]storage.DifficultyStats, []storage.GameRecord, error) {
+	stats, err := database.PlayerStats(player)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := database.History(player, historyPageSize, page*historyPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stats, records, nil
+}
+
+// Returns the zero based page requested, defaulting to the first.
+func historyPage(req *http.Request) int {
+	page, err := strconv.Atoi(req.FormValue("page"))
+	if err != nil || page < 0 {
+		return 0
+	}
+	return page
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+	"github.com/go-chi/chi/v5"
+)
+
+// leaderboardSize is the number of results shown at the top of a leaderboard.
+const leaderboardSize = 20
+
+// aroundRadius is the number of results shown either side of the player's own.
+const aroundRadius = 3
+
+func leaderboardIndexHandler(w http.ResponseWriter, req *http.Request) {
+	http.Redirect(w, req, "/leaderboard/"+generation.PresetNames[0], http.StatusSeeOther)
+}
+
+func leaderboardHandler(w http.ResponseWriter, req *http.Request) {
+	difficulty := req.Context().Value(DifficultyString).(string)
+	ranking := storage.ParseRanking(req.FormValue("by"))
+	player := playerName(w, req)
+	top, around, err := loadLeaderboard(difficulty, ranking, player, leaderboardSize)
+	if err != nil {
+		log.Println("loadLeaderboard:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	leaderboardData := view.FromLeaderboard(difficulty, ranking, top, around, player)
+	executeTemplate(w, "leaderboard.html", leaderboardData)
+}
+
+func apiLeaderboardHandler(w http.ResponseWriter, req *http.Request) {
+	difficulty := req.Context().Value(DifficultyString).(string)
+	ranking := storage.ParseRanking(req.FormValue("by"))
+	limit, err := strconv.Atoi(req.FormValue("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = leaderboardSize
+	}
+	player := playerName(w, req)
+	top, around, err := loadLeaderboard(difficulty, ranking, player, limit)
+	if err != nil {
+		log.Println("loadLeaderboard:", err)
+		writeJSONError(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, view.ToLeaderboardJSON(difficulty, ranking, top, around, player))
+}
+
+// Returns the top results of a difficulty and the results around the player's own.
+func loadLeaderboard(difficulty string, ranking storage.Ranking, player string, limit int) ([]storage.Result, []storage.Result, error) {
+	top, err := database.TopResults(difficulty, ranking, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	around, err := database.ResultsAround(difficulty, ranking, player, aroundRadius)
+	if err != nil {
+		return nil, nil, err
+	}
+	return top, around, nil
+}
+
+// Validates a won game by replaying it and adds it to the leaderboards. Rejected games are only logged.
+func submitResult(gameSave *storage.GameSave, name string) {
+	if err := database.SubmitResult(name, gameSave); err != nil {
+		log.Printf("SubmitResult: rejected %s: %s", name, err)
+	}
+}
+
+// DifficultyCtx stores a ranked difficulty from the URL, or the lives leaderboard of one, responding not found
+// for any other.
+func DifficultyCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		difficulty := chi.URLParam(req, string(DifficultyString))
+		if _, ok := generation.Presets[strings.TrimPrefix(difficulty, storage.LivesPrefix)]; !ok {
+			http.NotFound(w, req)
+			return
+		}
+		ctx := context.WithValue(req.Context(), DifficultyString, difficulty)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/deadly990/gominesweeper/controllers"
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/live"
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+var liveGames = live.NewHub(savedGames{})
+
+var errNoSpectators = errors.New("the owner of this game has disabled spectating")
+
+// savedGames plays games stored on disk for the live hub.
+type savedGames struct{}
+
+func (savedGames) Load(name string) (*game.Game, error) {
+	gameSave, err := storage.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return gameSave.ToGame(), nil
+}
+
+func (savedGames) Play(name string, player string, command controllers.ClickCommand) (*game.Game, error) {
+	move := game.Move{
+		Coordinate: game.Coordinate{X: command.XCoordinate, Y: command.YCoordinate},
+		Action:     command.Type.Action(),
+	}
+	_, played, err := playMove(name, player, move, command.Flagged)
+	return played, err
+}
+
+// Upgrades to a WebSocket that streams changes to a game and accepts moves from its players.
+// Other players join as spectators unless the owner has disabled spectating.
+func liveHandler(w http.ResponseWriter, req *http.Request) {
+	gameCtx := req.Context().Value(GameIDString).(string)
+	gameSave, err := storage.Load(gameCtx)
+	if err != nil {
+		http.Error(w, "game not found", 404)
+		return
+	}
+	if !canView(w, req, gameSave) {
+		http.Error(w, errNoSpectators.Error(), 403)
+		return
+	}
+	server := liveGames.Server(gameCtx, playerName(w, req), !canPlay(w, req, gameSave))
+	server.ServeHTTP(w, req)
+}
+
+// Lets the owner of a game allow or disable spectating. Disabling it disconnects anyone watching.
+func spectatorsHandler(w http.ResponseWriter, req *http.Request) {
+	gameCtx := req.Context().Value(GameIDString).(string)
+	unlock := lockGame(gameCtx)
+	defer unlock()
+	gameSave, err := storage.Load(gameCtx)
+	if err != nil {
+		http.Error(w, "game not found", 404)
+		return
+	}
+	if !ownsGame(w, req, gameSave) || gameSave.Player == "" {
+		http.Error(w, "only the owner of this game can change who may watch it", 403)
+		return
+	}
+	gameSave.NoSpectators = req.FormValue("allow") != "true"
+	saveGame(gameSave, gameCtx)
+	if gameSave.NoSpectators {
+		liveGames.CloseSpectators(gameCtx)
+	}
+	log.Printf("Game: %s spectators allowed: %t", gameCtx, !gameSave.NoSpectators)
+	http.Redirect(w, req, "/game/"+gameCtx, http.StatusSeeOther)
+}
@@ -0,0 +1,349 @@
+// Package live pushes changes to games over WebSockets as they are played.
+package live
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/deadly990/gominesweeper/controllers"
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/view"
+	"golang.org/x/net/websocket"
+)
+
+// sendBuffer is the number of updates queued for a connection before it is considered too slow and dropped.
+const sendBuffer = 32
+
+// Message types sent to clients.
+const (
+	Snapshot = "snapshot"
+	Diff     = "diff"
+	Presence = "presence"
+	Cursor   = "cursor"
+	Error    = "error"
+)
+
+// MoveCursor is the command type clients send when their pointer moves to another cell. It is shared with the
+// other players of a cooperative game instead of being played.
+const MoveCursor controllers.ClickType = "cursor"
+
+// Games loads and plays the games served by a Hub.
+type Games interface {
+	// Load returns the current state of a game.
+	Load(name string) (*game.Game, error)
+	// Play applies a click by a player to a game and returns its new state.
+	Play(name string, player string, command controllers.ClickCommand) (*game.Game, error)
+}
+
+// Cell is a tile whose visible value changed, encoded as in view.VisibleField.
+type Cell struct {
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Value int `json:"value"`
+}
+
+// Pointer is the cell another player is pointing at. Players are known by their ShortName, since the full name of
+// a guest is what identifies them to the server.
+type Pointer struct {
+	Player string `json:"player"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+}
+
+// Update is a message sent to clients. Snapshots carry the whole visible field and diffs only the changed cells.
+// Presence updates are sent when someone starts or stops watching, and cursor updates when a player points at a cell.
+type Update struct {
+	Type      string  `json:"type"`
+	Field     [][]int `json:"field,omitempty"`
+	Cells     []Cell  `json:"cells,omitempty"`
+	Remaining int     `json:"remaining"`
+	Won       bool    `json:"won"`
+	Lost      bool    `json:"lost"`
+	// Viewers counts every connection to the game and Spectators those of players other than its owner.
+	Viewers    int      `json:"viewers"`
+	Spectators int      `json:"spectators"`
+	Pointer    *Pointer `json:"pointer,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Hub tracks the connections watching each game and broadcasts every change to them.
+type Hub struct {
+	games    Games
+	mutex    sync.Mutex
+	channels map[string]*channel
+}
+
+// channel is the set of connections to one game and the field they were last sent.
+type channel struct {
+	mutex       sync.Mutex
+	field       [][]int
+	subscribers map[*subscriber]bool
+}
+
+type subscriber struct {
+	conn      *websocket.Conn
+	updates   chan Update
+	player    string
+	spectator bool
+}
+
+func NewHub(games Games) *Hub {
+	return &Hub{games: games, channels: map[string]*channel{}}
+}
+
+// Returns the WebSocket server for a game. Browsers may only connect from the same host, other clients
+// that send no Origin are accepted.
+func (hub *Hub) Server(name string, player string, spectator bool) websocket.Server {
+	return websocket.Server{
+		Handshake: SameOrigin,
+		Handler: func(conn *websocket.Conn) {
+			hub.serve(conn, name, player, spectator)
+		},
+	}
+}
+
+// SameOrigin is a WebSocket handshake that rejects browsers connecting from another host.
+func SameOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host != req.Host {
+		return fmt.Errorf("cross origin connection from %s", origin)
+	}
+	config.Origin = parsed
+	return nil
+}
+
+// Returns the number of connections watching a game.
+func (hub *Hub) Viewers(name string) int {
+	viewers, _ := hub.counts(name)
+	return viewers
+}
+
+// Returns the number of connections watching a game that are not its owner's.
+func (hub *Hub) Spectators(name string) int {
+	_, spectators := hub.counts(name)
+	return spectators
+}
+
+func (hub *Hub) counts(name string) (int, int) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	if gameChannel, ok := hub.channels[name]; ok {
+		return gameChannel.counts()
+	}
+	return 0, 0
+}
+
+// Disconnects everyone watching a game other than its owner.
+func (hub *Hub) CloseSpectators(name string) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	if gameChannel, ok := hub.channels[name]; ok {
+		for listener := range gameChannel.subscribers {
+			if listener.spectator {
+				listener.conn.Close()
+			}
+		}
+	}
+}
+
+// Sends the cells of a game that changed since the last update to everyone watching it.
+func (hub *Hub) Publish(name string, current *game.Game) {
+	hub.publish(name, current, false)
+}
+
+// Sends an update to everyone watching a game even if none of its cells changed, for changes that are not
+// visible on the field such as another player joining.
+func (hub *Hub) Touch(name string, current *game.Game) {
+	hub.publish(name, current, true)
+}
+
+func (hub *Hub) publish(name string, current *game.Game, always bool) {
+	hub.mutex.Lock()
+	channel, ok := hub.channels[name]
+	hub.mutex.Unlock()
+	if !ok {
+		return
+	}
+	channel.mutex.Lock()
+	defer channel.mutex.Unlock()
+	field := view.VisibleField(*current)
+	update := newUpdate(Diff, *current)
+	update.Viewers, update.Spectators = channel.counts()
+	update.Cells = diff(channel.field, field)
+	channel.field = field
+	if len(update.Cells) == 0 && !always {
+		return
+	}
+	for subscriber := range channel.subscribers {
+		subscriber.send(update)
+	}
+}
+
+func (hub *Hub) serve(conn *websocket.Conn, name string, player string, spectator bool) {
+	defer conn.Close()
+	current, err := hub.games.Load(name)
+	if err != nil {
+		websocket.JSON.Send(conn, Update{Type: Error, Error: "game not found"})
+		return
+	}
+	subscriber := &subscriber{conn: conn, updates: make(chan Update, sendBuffer), player: player, spectator: spectator}
+	hub.subscribe(name, subscriber, current)
+	defer hub.unsubscribe(name, subscriber)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for update := range subscriber.updates {
+			if err := websocket.JSON.Send(conn, update); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var command controllers.ClickCommand
+		if err := websocket.JSON.Receive(conn, &command); err != nil {
+			break
+		}
+		if command.Type == MoveCursor {
+			if !spectator {
+				hub.point(name, subscriber, command.XCoordinate, command.YCoordinate)
+			}
+			continue
+		}
+		played, err := hub.games.Play(name, player, command)
+		if err != nil {
+			subscriber.send(Update{Type: Error, Error: err.Error()})
+			continue
+		}
+		hub.Publish(name, played)
+	}
+	hub.unsubscribe(name, subscriber)
+	<-done
+}
+
+// Sends the cell a player is pointing at to everyone else watching the game.
+func (hub *Hub) point(name string, from *subscriber, x int, y int) {
+	hub.mutex.Lock()
+	gameChannel, ok := hub.channels[name]
+	hub.mutex.Unlock()
+	if !ok {
+		return
+	}
+	gameChannel.mutex.Lock()
+	defer gameChannel.mutex.Unlock()
+	update := Update{Type: Cursor, Pointer: &Pointer{Player: view.ShortName(from.player), X: x, Y: y}}
+	update.Viewers, update.Spectators = gameChannel.counts()
+	for listener := range gameChannel.subscribers {
+		if listener != from {
+			listener.send(update)
+		}
+	}
+}
+
+// Adds a subscriber to a game's channel and queues a snapshot of the game for it.
+func (hub *Hub) subscribe(name string, listener *subscriber, current *game.Game) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	gameChannel, ok := hub.channels[name]
+	if !ok {
+		gameChannel = &channel{subscribers: map[*subscriber]bool{}}
+		hub.channels[name] = gameChannel
+	}
+	gameChannel.mutex.Lock()
+	defer gameChannel.mutex.Unlock()
+	gameChannel.subscribers[listener] = true
+	if gameChannel.field == nil {
+		gameChannel.field = view.VisibleField(*current)
+	}
+	update := newUpdate(Snapshot, *current)
+	update.Viewers, update.Spectators = gameChannel.counts()
+	update.Field = gameChannel.field
+	listener.send(update)
+	gameChannel.announce(listener)
+}
+
+// Removes a subscriber from a game's channel, closing the channel once nobody is watching.
+func (hub *Hub) unsubscribe(name string, subscriber *subscriber) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	channel, ok := hub.channels[name]
+	if !ok {
+		return
+	}
+	channel.mutex.Lock()
+	defer channel.mutex.Unlock()
+	if !channel.subscribers[subscriber] {
+		return
+	}
+	delete(channel.subscribers, subscriber)
+	close(subscriber.updates)
+	if len(channel.subscribers) == 0 {
+		delete(hub.channels, name)
+		return
+	}
+	channel.announce(nil)
+}
+
+// Returns the number of subscribers and how many of them are spectators. The channel must be locked.
+func (channel *channel) counts() (int, int) {
+	spectators := 0
+	for listener := range channel.subscribers {
+		if listener.spectator {
+			spectators++
+		}
+	}
+	return len(channel.subscribers), spectators
+}
+
+// Sends the current counts to every subscriber other than the one that caused the change.
+// The channel must be locked.
+func (channel *channel) announce(except *subscriber) {
+	update := Update{Type: Presence}
+	update.Viewers, update.Spectators = channel.counts()
+	for listener := range channel.subscribers {
+		if listener != except {
+			listener.send(update)
+		}
+	}
+}
+
+// Queues an update without blocking. A connection that has fallen behind is closed rather than sent
+// a partial set of diffs.
+func (subscriber *subscriber) send(update Update) {
+	select {
+	case subscriber.updates <- update:
+	default:
+		log.Println("live: closing a connection that fell behind")
+		subscriber.conn.Close()
+	}
+}
+
+func newUpdate(kind string, current game.Game) Update {
+	return Update{
+		Type:      kind,
+		Remaining: view.FromGame(current, "").Remaining,
+		Won:       current.Won(),
+		Lost:      current.Lost(),
+	}
+}
+
+// Returns the cells whose values differ between two fields of the same size.
+func diff(before [][]int, after [][]int) []Cell {
+	cells := []Cell{}
+	for y, row := range after {
+		for x, value := range row {
+			if before == nil || before[y][x] != value {
+				cells = append(cells, Cell{X: x, Y: y, Value: value})
+			}
+		}
+	}
+	return cells
+}
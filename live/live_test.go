@@ -0,0 +1,231 @@
+package live
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deadly990/gominesweeper/controllers"
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"golang.org/x/net/websocket"
+)
+
+// ownerName is the only player allowed to play the test games. It is longer than a short name so tests can check
+// which form of it is sent.
+const ownerName = "owner-of-the-game"
+
+// memoryGames keeps games in memory and only lets the owner play them.
+type memoryGames struct {
+	mutex sync.Mutex
+	games map[string]*game.Game
+}
+
+func (games *memoryGames) Load(name string) (*game.Game, error) {
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	current, ok := games.games[name]
+	if !ok {
+		return nil, fmt.Errorf("no game %s", name)
+	}
+	return current, nil
+}
+
+func (games *memoryGames) Play(name string, player string, command controllers.ClickCommand) (*game.Game, error) {
+	if player != ownerName {
+		return nil, fmt.Errorf("only the owner can play")
+	}
+	current, err := games.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	played := controllers.RunClickCommand(*current, command)
+	games.games[name] = &played
+	return &played, nil
+}
+
+// Starts a server for a hub with one game, where each connection names its player in the query.
+func startTestServer(test *testing.T) (*Hub, *httptest.Server) {
+	board, err := generation.FromLayout([]string{
+		"....",
+		"....",
+		"....",
+		"...*",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	hub := NewHub(&memoryGames{games: map[string]*game.Game{"test": game.NewGame(*board)}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		player := req.URL.Query().Get("player")
+		websocketServer := hub.Server(strings.TrimPrefix(req.URL.Path, "/"), player, player != ownerName)
+		websocketServer.ServeHTTP(w, req)
+	}))
+	test.Cleanup(server.Close)
+	return hub, server
+}
+
+func dial(test *testing.T, server *httptest.Server, name string, player string) *websocket.Conn {
+	address := "ws" + strings.TrimPrefix(server.URL, "http") + "/" + name + "?player=" + player
+	conn, err := websocket.Dial(address, "", server.URL)
+	if err != nil {
+		log.Printf("Error connecting: %s", err)
+		test.FailNow()
+	}
+	test.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func receive(test *testing.T, conn *websocket.Conn) Update {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	update := Update{}
+	if err := websocket.JSON.Receive(conn, &update); err != nil {
+		log.Printf("Error receiving update: %s", err)
+		test.FailNow()
+	}
+	return update
+}
+
+func TestSnapshotThenDiff(test *testing.T) {
+	_, server := startTestServer(test)
+	conn := dial(test, server, "test", ownerName)
+
+	snapshot := receive(test, conn)
+	if snapshot.Type != Snapshot || len(snapshot.Field) != 4 || snapshot.Field[0][0] != -1 || snapshot.Remaining != 1 {
+		log.Printf("Expected a snapshot of the hidden board. Actual: %+v", snapshot)
+		test.Fail()
+	}
+
+	websocket.JSON.Send(conn, controllers.ClickCommand{Type: "right", YCoordinate: 3, XCoordinate: 3})
+	update := receive(test, conn)
+	expected := []Cell{{X: 3, Y: 3, Value: -2}}
+	if update.Type != Diff || len(update.Cells) != 1 || update.Cells[0] != expected[0] || update.Remaining != 0 {
+		log.Printf("Expected a diff of the flagged cell only. Actual: %+v", update)
+		test.Fail()
+	}
+
+	websocket.JSON.Send(conn, controllers.ClickCommand{Type: "left", YCoordinate: 0, XCoordinate: 0})
+	update = receive(test, conn)
+	if update.Type != Diff || len(update.Cells) != 15 || !update.Won {
+		log.Printf("Expected a diff of the 15 revealed cells winning the game. Actual: %+v", update)
+		test.Fail()
+	}
+}
+
+func TestStackedFlagsAreDiffed(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"2.",
+		"..",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	hub := NewHub(&memoryGames{games: map[string]*game.Game{"stacked": game.NewGame(*board)}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		websocketServer := hub.Server("stacked", ownerName, false)
+		websocketServer.ServeHTTP(w, req)
+	}))
+	test.Cleanup(server.Close)
+	conn := dial(test, server, "stacked", ownerName)
+	receive(test, conn)
+
+	for flags, expected := range []int{-2, -3} {
+		websocket.JSON.Send(conn, controllers.ClickCommand{Type: "right", YCoordinate: 0, XCoordinate: 0})
+		update := receive(test, conn)
+		if update.Type != Diff || len(update.Cells) != 1 || update.Cells[0].Value != expected {
+			log.Printf("Expected a diff of the tile with %d flags. Actual: %+v", flags+1, update)
+			test.Fail()
+		}
+	}
+}
+
+func TestUpdatesReachEveryConnection(test *testing.T) {
+	hub, server := startTestServer(test)
+	owner := dial(test, server, "test", ownerName)
+	receive(test, owner)
+	spectator := dial(test, server, "test", "spectator")
+	if snapshot := receive(test, spectator); snapshot.Viewers != 2 || snapshot.Spectators != 1 || hub.Spectators("test") != 1 {
+		log.Printf("Expected two viewers, one a spectator. Actual: %+v", snapshot)
+		test.Fail()
+	}
+	if presence := receive(test, owner); presence.Type != Presence || presence.Spectators != 1 {
+		log.Printf("Expected the owner to be told about the spectator. Actual: %+v", presence)
+		test.Fail()
+	}
+
+	websocket.JSON.Send(spectator, controllers.ClickCommand{Type: "left", YCoordinate: 0, XCoordinate: 0})
+	if update := receive(test, spectator); update.Type != Error {
+		log.Printf("Expected spectator move to be rejected. Actual: %+v", update)
+		test.Fail()
+	}
+
+	websocket.JSON.Send(owner, controllers.ClickCommand{Type: "left", YCoordinate: 2, XCoordinate: 2})
+	ownerUpdate := receive(test, owner)
+	spectatorUpdate := receive(test, spectator)
+	if spectatorUpdate.Type != Diff || len(spectatorUpdate.Cells) != 1 || len(ownerUpdate.Cells) != 1 {
+		log.Printf("Expected both connections to receive the diff. Actual: %+v %+v", ownerUpdate, spectatorUpdate)
+		test.Fail()
+	}
+}
+
+func TestCrossOriginRejected(test *testing.T) {
+	_, server := startTestServer(test)
+	address := "ws" + strings.TrimPrefix(server.URL, "http") + "/test"
+	if _, err := websocket.Dial(address, "", "http://example.com"); err == nil {
+		log.Printf("Expected a connection from another origin to be rejected.")
+		test.Fail()
+	}
+}
+
+func TestCloseSpectators(test *testing.T) {
+	hub, server := startTestServer(test)
+	owner := dial(test, server, "test", ownerName)
+	receive(test, owner)
+	spectator := dial(test, server, "test", "spectator")
+	receive(test, spectator)
+	receive(test, owner)
+
+	hub.CloseSpectators("test")
+	spectator.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := websocket.JSON.Receive(spectator, &Update{}); err == nil {
+		log.Printf("Expected the spectator to be disconnected.")
+		test.Fail()
+	}
+	if presence := receive(test, owner); presence.Type != Presence || presence.Spectators != 0 || presence.Viewers != 1 {
+		log.Printf("Expected the owner to be told the spectator left. Actual: %+v", presence)
+		test.Fail()
+	}
+}
+
+func TestCursorsReachOtherPlayers(test *testing.T) {
+	_, server := startTestServer(test)
+	owner := dial(test, server, "test", ownerName)
+	receive(test, owner)
+	spectator := dial(test, server, "test", "spectator")
+	receive(test, spectator)
+	receive(test, owner)
+
+	websocket.JSON.Send(spectator, controllers.ClickCommand{Type: MoveCursor, YCoordinate: 1, XCoordinate: 1})
+	websocket.JSON.Send(owner, controllers.ClickCommand{Type: MoveCursor, YCoordinate: 2, XCoordinate: 1})
+	update := receive(test, spectator)
+	expected := Pointer{Player: "owner-of", X: 1, Y: 2}
+	if update.Type != Cursor || update.Pointer == nil || *update.Pointer != expected {
+		log.Printf("Expected the owner's cursor under their short name and not the spectator's. Actual: %+v", update)
+		test.Fail()
+	}
+
+	websocket.JSON.Send(owner, controllers.ClickCommand{Type: "right", YCoordinate: 3, XCoordinate: 3})
+	if update := receive(test, owner); update.Type != Diff {
+		log.Printf("Expected the owner not to be sent their own cursor. Actual: %+v", update)
+		test.Fail()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -12,10 +13,14 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/deadly990/gominesweeper/botapi"
+	"github.com/deadly990/gominesweeper/campaign"
 	"github.com/deadly990/gominesweeper/game"
 	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/solver"
 	"github.com/deadly990/gominesweeper/storage"
 	"github.com/deadly990/gominesweeper/view"
 	"github.com/go-chi/chi/v5"
@@ -28,8 +33,33 @@ type contextName string
 
 const GameIDString contextName = "gameId"
 const ClickLocationString contextName = "clickLocation"
+const PackIDString contextName = "packId"
+const LevelIDString contextName = "levelId"
+const DateString contextName = "date"
+const DifficultyString contextName = "difficulty"
+const RaceIDString contextName = "raceId"
+const MatchIDString contextName = "matchId"
+const RunIDString contextName = "runId"
+
+const PlayerCookie = "player"
+const SessionCookie = "session"
+
+// FragmentHeader is sent by fragments.js, following htmx, to request a partial page.
+const FragmentHeader = "HX-Request"
+
+var database *storage.DB
 
 func main() {
+	var err error
+	database, err = storage.Open(storage.DatabasePath)
+	if err != nil {
+		log.Fatal("Open:", err)
+	}
+	defer database.Close()
+	if err := database.IndexSaves(); err != nil {
+		log.Println("IndexSaves:", err)
+	}
+
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
@@ -44,10 +74,111 @@ func main() {
 		r.Route("/load", func(r chi.Router) {
 			r.Get("/", loadHandler)
 		})
-		r.Route(fmt.Sprintf("/{%s}/click/{%s}", GameIDString, ClickLocationString), func(r chi.Router) {
+		r.Route(fmt.Sprintf("/{%s}", GameIDString), func(r chi.Router) {
+			r.Use(GameCtx)
+			r.Get("/", viewHandler)
+			r.Get("/replay", replayHandler)
+			r.Get("/live", liveHandler)
+			r.Post("/spectators", spectatorsHandler)
+			r.Post("/rewind", rewindHandler)
+			r.Post("/join", joinGameHandler)
+			r.Route(fmt.Sprintf("/click/{%s}", ClickLocationString), func(r chi.Router) {
+				r.Use(ClickCtx)
+				r.Get("/", moveHandler(game.Reveal))
+			})
+			r.Route(fmt.Sprintf("/flag/{%s}", ClickLocationString), func(r chi.Router) {
+				r.Use(ClickCtx)
+				r.Get("/", moveHandler(game.Flag))
+			})
+			r.Route(fmt.Sprintf("/chord/{%s}", ClickLocationString), func(r chi.Router) {
+				r.Use(ClickCtx)
+				r.Get("/", moveHandler(game.Chord))
+			})
+		})
+	})
+	r.Route("/account", func(r chi.Router) {
+		r.Get("/", accountHandler)
+		r.Post("/register", registerHandler)
+		r.Post("/login", loginHandler)
+		r.Post("/logout", logoutHandler)
+		r.Post("/bots", createBotHandler)
+	})
+	r.Get("/history", historyHandler)
+	r.Route("/leaderboard", func(r chi.Router) {
+		r.Get("/", leaderboardIndexHandler)
+		r.Route(fmt.Sprintf("/{%s}", DifficultyString), func(r chi.Router) {
+			r.Use(DifficultyCtx)
+			r.Get("/", leaderboardHandler)
+		})
+	})
+	r.Route("/race", func(r chi.Router) {
+		r.Get("/", racesHandler)
+		r.Post("/", createRaceHandler)
+		r.Route(fmt.Sprintf("/{%s}", RaceIDString), func(r chi.Router) {
+			r.Use(RaceCtx)
+			r.Get("/", raceHandler)
+			r.Post("/join", joinRaceHandler)
+			r.Post("/ready", readyHandler)
+			r.Post("/leave", leaveRaceHandler)
+			r.Get("/live", raceLiveHandler)
+		})
+	})
+	r.Route("/run", func(r chi.Router) {
+		r.Get("/", runsHandler)
+		r.Post("/", createRunHandler)
+		r.Route(fmt.Sprintf("/{%s}", RunIDString), func(r chi.Router) {
+			r.Use(RunCtx)
+			r.Get("/", runHandler)
+		})
+	})
+	r.Route("/versus", func(r chi.Router) {
+		r.Post("/", createMatchHandler)
+		r.Route(fmt.Sprintf("/{%s}", MatchIDString), func(r chi.Router) {
+			r.Use(MatchCtx)
+			r.Get("/", matchHandler)
+			r.Post("/join", joinMatchHandler)
+			r.Get("/live", matchLiveHandler)
+			r.Route(fmt.Sprintf("/click/{%s}", ClickLocationString), func(r chi.Router) {
+				r.Use(ClickCtx)
+				r.Get("/", matchMoveHandler)
+			})
+		})
+	})
+	bots := botapi.NewServer(database, botapi.DefaultLimit)
+	bots.Lock = lockGame
+	bots.Played = liveGames.Publish
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/history", apiHistoryHandler)
+		r.Mount("/bot", bots.Routes())
+		r.Route(fmt.Sprintf("/race/{%s}", RaceIDString), func(r chi.Router) {
+			r.Use(RaceCtx)
+			r.Get("/", apiRaceHandler)
+		})
+		r.Route(fmt.Sprintf("/run/{%s}", RunIDString), func(r chi.Router) {
+			r.Use(RunCtx)
+			r.Get("/", apiRunHandler)
+		})
+		r.Route(fmt.Sprintf("/leaderboard/{%s}", DifficultyString), func(r chi.Router) {
+			r.Use(DifficultyCtx)
+			r.Get("/", apiLeaderboardHandler)
+		})
+		r.Route(fmt.Sprintf("/game/{%s}", GameIDString), func(r chi.Router) {
 			r.Use(GameCtx)
-			r.Use(ClickCtx)
-			r.Get("/", clickHandler)
+			r.Get("/", apiGameHandler)
+		})
+	})
+	r.Route("/campaign", func(r chi.Router) {
+		r.Get("/", campaignHandler)
+		r.Route(fmt.Sprintf("/{%s}/{%s}", PackIDString, LevelIDString), func(r chi.Router) {
+			r.Use(LevelCtx)
+			r.Get("/", levelHandler)
+		})
+	})
+	r.Route("/challenge", func(r chi.Router) {
+		r.Get("/", dailyHandler)
+		r.Route(fmt.Sprintf("/{%s}/results", DateString), func(r chi.Router) {
+			r.Use(DateCtx)
+			r.Get("/", resultsHandler)
 		})
 	})
 	r.Get("/test", http.HandlerFunc(rootHandler))
@@ -55,17 +186,15 @@ func main() {
 	flag.Parse()
 	fs := http.FileServer(http.Dir("./static"))
 	r.Handle("/static/*", http.StripPrefix("/static/", fs))
-	err := http.ListenAndServe(*addr, r)
+	err = http.ListenAndServe(*addr, r)
 	if err != nil {
 		log.Fatal("ListenAndServe:", err)
 	}
 }
 
 func rootHandler(w http.ResponseWriter, req *http.Request) {
-	err := mainPageTemplate.ExecuteTemplate(w, "mainpage.html", nil)
-	if err != nil {
-		log.Fatal("ExecuteTemplate:", err)
-	}
+	username, _ := signedIn(req)
+	executeTemplate(w, "mainpage.html", view.AccountData{Username: username})
 }
 
 func generateHandler(w http.ResponseWriter, req *http.Request) {
@@ -75,54 +204,277 @@ func generateHandler(w http.ResponseWriter, req *http.Request) {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	topology, err := generation.ParseTopology(req.FormValue("topology"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	neighborhood, err := parseNeighborhood(req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	perTile, err := parsePerTile(req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	layers, err := parseLayers(req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	lives, err := parseLives(req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
 	random := rand.New(rand.NewSource(time.Now().UnixNano()))
-	newBoard, boardErr := generation.NewBoard(mines, width, height, random.Int63())
+	// Each layer of a three dimensional board is the size and holds the mines of the chosen difficulty.
+	newBoard, boardErr := generation.NewMultiMineBoard(mines*layers, perTile, width, height*layers, random.Int63())
 	if boardErr != nil {
-		log.Println("NewBoard:", boardErr)
+		log.Println("NewMultiMineBoard:", boardErr)
 		http.Error(w, boardErr.Error(), 500)
 		return
 	}
+	if topology != generation.Plane {
+		newBoard = newBoard.OnTopology(topology)
+	}
+	if neighborhood != nil {
+		if newBoard, err = newBoard.WithNeighborhood(neighborhood); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	}
+	if layers > 1 {
+		if newBoard, err = newBoard.InLayers(layers); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	}
 
 	game := game.NewGame(*newBoard)
+	game.Lives = lives
 	gameName := generateName(rand.Int63())
-	mineView := view.FromGame(*game, gameName)
-	mainData := view.MainData{Mine: mineView}
-	mainPageTemplate.ExecuteTemplate(w, "game.html", mainData)
-	storage.FromGame(*game).Save(gameName)
+	gameSave := storage.FromGame(*game)
+	gameSave.Player = playerName(w, req)
+	gameSave.Started = time.Now().UnixMilli()
+	gameSave.Difficulty = req.FormValue("difficulty")
+	if req.FormValue("coop") == "true" {
+		gameSave.Coop = true
+		gameSave.Contributors = []string{gameSave.Player}
+	}
+	if req.FormValue("training") == "true" {
+		if gameSave.Coop || !canRate(*newBoard) {
+			http.Error(w, "training games are played alone on flat boards with one mine per tile", 400)
+			return
+		}
+		gameSave.Training = true
+	}
+	if canRate(*newBoard) {
+		rating := solver.Rate(*newBoard, solver.DefaultStart(*newBoard))
+		gameSave.Rating = &rating
+	}
+	renderGame(w, req, gameSave, game, gameName)
+	saveGame(gameSave, gameName)
 }
 
-func clickHandler(w http.ResponseWriter, req *http.Request) {
-	// Handle click
-	gameCtx := req.Context().Value(GameIDString).(string)
-	clickCtx := req.Context().Value(ClickLocationString).(string)
+// Returns the neighborhood chosen on the main page: a named one, or the offsets given for a custom one.
+func parseNeighborhood(req *http.Request) (generation.Neighborhood, error) {
+	if req.FormValue("neighborhood") == "custom" {
+		if strings.TrimSpace(req.FormValue("offsets")) == "" {
+			return nil, fmt.Errorf("a custom neighborhood needs offsets")
+		}
+		return generation.ParseNeighborhood(req.FormValue("offsets"))
+	}
+	return generation.ParseNeighborhood(req.FormValue("neighborhood"))
+}
 
-	coord, err := parseClickLocation(clickCtx)
-	if err != nil {
-		log.Fatal("parseClickLocation:", err)
+// Returns the most mines a tile may hold chosen on the main page, 1 if none was chosen.
+func parsePerTile(req *http.Request) (int, error) {
+	if req.FormValue("perTile") == "" {
+		return 1, nil
 	}
-	gameSave, err := storage.Load(gameCtx)
-	if err != nil {
-		// Return to mainpage is there was an error loading from click.
-		// Likely would be due to user manipulation of url.
-		err := mainPageTemplate.ExecuteTemplate(w, "mainpage.html", nil)
+	perTile, err := strconv.Atoi(req.FormValue("perTile"))
+	if err != nil || perTile < 1 || perTile > generation.MaxMinesPerTile {
+		return 0, fmt.Errorf("mines per tile must be between 1 and %d", generation.MaxMinesPerTile)
+	}
+	return perTile, nil
+}
+
+// Returns the number of layers chosen on the main page, 1 for a flat board if none was chosen.
+func parseLayers(req *http.Request) (int, error) {
+	if req.FormValue("layers") == "" {
+		return 1, nil
+	}
+	layers, err := strconv.Atoi(req.FormValue("layers"))
+	if err != nil || layers < 1 || layers > generation.MaxLayers {
+		return 0, fmt.Errorf("layers must be between 1 and %d", generation.MaxLayers)
+	}
+	return layers, nil
+}
+
+// Returns the number of lives chosen on the main page, zero for a classic game lost on the first mine hit.
+func parseLives(req *http.Request) (int, error) {
+	if req.FormValue("lives") == "" {
+		return 0, nil
+	}
+	lives, err := strconv.Atoi(req.FormValue("lives"))
+	if err != nil || lives < 1 || lives > game.MaxLives {
+		return 0, fmt.Errorf("lives must be between 1 and %d", game.MaxLives)
+	}
+	if lives == 1 {
+		return 0, nil
+	}
+	return lives, nil
+}
+
+// Returns a handler that plays a move with the given action at the clicked tile.
+func moveHandler(action game.Action) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		gameCtx := req.Context().Value(GameIDString).(string)
+		clickCtx := req.Context().Value(ClickLocationString).(string)
+
+		coord, err := parseClickLocation(clickCtx)
 		if err != nil {
-			log.Fatal("ExecuteTemplate:", err)
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		move := game.Move{Coordinate: coord, Action: action}
+		gameSave, game, err := playMove(gameCtx, playerName(w, req), move, parseFlagged(req))
+		if errors.Is(err, errNotOwner) {
+			http.Error(w, err.Error(), 403)
+			return
 		}
+		if errors.Is(err, errRaceNotStarted) || errors.Is(err, errRunOver) {
+			http.Error(w, err.Error(), 409)
+			return
+		}
+		if err != nil {
+			// Return to mainpage is there was an error loading from click.
+			// Likely would be due to user manipulation of url.
+			executeTemplate(w, "mainpage.html", nil)
+			return
+		}
+
+		// Display updated board
+		renderGame(w, req, gameSave, game, gameCtx)
+
+		log.Printf("Game: %s %s: %s", gameCtx, action, clickCtx)
 	}
-	game := gameSave.ToGame()
-	game.Move(coord, game.Clear)
+}
+
+var errNotOwner = errors.New("only the owner of this game can play it")
+
+// gameLock is the mutex of a game and the number of requests holding or waiting for it.
+type gameLock struct {
+	sync.Mutex
+	users int
+}
+
+// gameLocks holds a mutex per game name so changes from several requests or connections are applied in turn.
+// A game's mutex is removed once nobody holds or waits for it.
+var gameLocks = map[string]*gameLock{}
+var gameLocksMutex sync.Mutex
+
+// Locks a game against other changes, returning the function that unlocks it.
+func lockGame(name string) func() {
+	gameLocksMutex.Lock()
+	lock, ok := gameLocks[name]
+	if !ok {
+		lock = &gameLock{}
+		gameLocks[name] = lock
+	}
+	lock.users++
+	gameLocksMutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		gameLocksMutex.Lock()
+		defer gameLocksMutex.Unlock()
+		lock.users--
+		if lock.users == 0 {
+			delete(gameLocks, name)
+		}
+	}
+}
 
-	storage.FromGame(*game).Save(gameCtx)
+// Plays a move by a player on a saved game, saves the result and publishes it to anyone watching live.
+// A flag move with the flag state the player wants is ignored if the tile is already in that state, so
+// players flagging the same tile at once do not undo each other.
+func playMove(name string, player string, move game.Move, flagged *bool) (*storage.GameSave, *game.Game, error) {
+	defer lockGame(name)()
 
-	// Display updated board
-	mineView := view.FromGame(*game, gameCtx)
-	mainData := view.MainData{Mine: mineView}
-	err = mainPageTemplate.ExecuteTemplate(w, "game.html", mainData)
+	gameSave, err := storage.Load(name)
 	if err != nil {
-		log.Fatal("ExecuteTemplate:", err)
+		return nil, nil, err
+	}
+	if !gameSave.PlayableBy(player) {
+		return nil, nil, errNotOwner
+	}
+	if gameSave.Started > time.Now().UnixMilli() {
+		return nil, nil, errRaceNotStarted
+	}
+	if err := checkRun(gameSave); err != nil {
+		return nil, nil, err
+	}
+	move.Elapsed = gameSave.Elapsed(time.Now())
+	if gameSave.Coop {
+		move.Player = player
+	}
+	played := gameSave.ToGame()
+	if move.Action == game.Flag && flagged != nil && played.Board.IsInRange(move.Y, move.X) && played.Flagged(move.Coordinate) == *flagged {
+		return gameSave, played, nil
+	}
+	gameSave.Review(*played, move)
+	if played.Play(move) && isFirstReveal(played.Moves) && gameSave.Layout == nil && gameSave.Daily == "" && canRate(played.Board) {
+		// Classic games start wherever the player first clicks.
+		rating := solver.Rate(played.Board, move.Coordinate)
+		gameSave.Rating = &rating
 	}
 
-	log.Printf("Game: %s Click: %s", gameCtx, clickCtx)
+	gameSave.Record(*played)
+	if played.Over() && gameSave.Finished == 0 {
+		finishGame(gameSave, played, name)
+	}
+	saveGame(gameSave, name)
+	liveGames.Publish(name, played)
+	if gameSave.Race != "" {
+		updateRace(gameSave, played)
+	}
+	return gameSave, played, nil
+}
+
+// Returns true if the solver can rate a board quickly enough to do so while a request waits. It reasons about
+// one mine per tile, so boards that stack mines are not rated, and layered boards are too slow to solve.
+func canRate(board generation.Board) bool {
+	return board.Counts == nil && board.Layers <= 1
+}
+
+// Returns true if the last move is the only useful reveal of the game.
+func isFirstReveal(moves []game.Move) bool {
+	for index, move := range moves {
+		if move.Action == game.Reveal && move.Useful {
+			return index == len(moves)-1
+		}
+	}
+	return false
+}
+
+// Displays a game by its ID. This is the link owners share to let others watch read-only.
+func viewHandler(w http.ResponseWriter, req *http.Request) {
+	gameCtx := req.Context().Value(GameIDString).(string)
+	gameSave, err := storage.Load(gameCtx)
+	if err != nil {
+		http.Error(w, "game not found", 404)
+		return
+	}
+	if !canView(w, req, gameSave) {
+		http.Error(w, errNoSpectators.Error(), 403)
+		return
+	}
+	renderGame(w, req, gameSave, gameSave.ToGame(), gameCtx)
 }
 
 func loadHandler(w http.ResponseWriter, req *http.Request) {
@@ -130,29 +482,110 @@ func loadHandler(w http.ResponseWriter, req *http.Request) {
 	gameSave, err := storage.Load(saveName)
 	if err != nil {
 		// Return to main page if there was an error loading user input save name.
-		err := mainPageTemplate.ExecuteTemplate(w, "mainpage.html", nil)
-		if err != nil {
-			log.Fatal("ExecuteTemplate:", err)
-		}
+		executeTemplate(w, "mainpage.html", nil)
+		return
+	}
+	if !canView(w, req, gameSave) {
+		http.Error(w, errNoSpectators.Error(), 403)
 		return
 	}
 	game := gameSave.ToGame()
-	mineView := view.FromGame(*game, saveName)
-	mainData := view.MainData{Mine: mineView}
-	err = mainPageTemplate.ExecuteTemplate(w, "game.html", mainData)
-	if err != nil {
-		log.Fatal("ExecuteTemplate:", err)
+	renderGame(w, req, gameSave, game, saveName)
+}
+
+// Records the end of a game and the progress it earns the player in campaign or challenge modes,
+// or on the leaderboards.
+func finishGame(gameSave *storage.GameSave, game *game.Game, name string) {
+	gameSave.Finished = time.Now().UnixMilli()
+	gameSave.Won = game.Won()
+	if gameSave.Level != "" && game.Won() {
+		completeLevel(gameSave.Player, gameSave.Level)
+	}
+	if gameSave.Daily != "" {
+		finishAttempt(gameSave, game.Won())
+	}
+	if _, ranked := generation.Presets[gameSave.Difficulty]; ranked && game.Won() && !gameSave.Training {
+		submitResult(gameSave, name)
+	}
+	if gameSave.Run != "" {
+		advanceRun(gameSave, game, name)
+	}
+}
+
+// Saves a game to disk and updates its entry in the games index.
+func saveGame(gameSave *storage.GameSave, name string) {
+	if err := gameSave.Save(name); err != nil {
+		log.Println("GameSave#Save:", err)
+		return
+	}
+	if err := database.IndexGame(name, gameSave); err != nil {
+		log.Println("IndexGame:", err)
 	}
 }
 
+// Renders the game page, including the campaign level being played if there is one.
+// Players other than the owner of the game see it read-only. Fragment requests receive only the board
+// and its status so the page can be updated in place.
+func renderGame(w http.ResponseWriter, req *http.Request, gameSave *storage.GameSave, game *game.Game, name string) {
+	mineView := view.FromGame(*game, name)
+	mineView.ReadOnly = !canPlay(w, req, gameSave)
+	username, _ := signedIn(req)
+	mainData := view.MainData{
+		Mine:         mineView,
+		Player:       username,
+		Daily:        gameSave.Daily,
+		Stats:        game.Stats(),
+		Spectators:   liveGames.Spectators(name),
+		NoSpectators: gameSave.NoSpectators,
+		Race:         gameSave.Race,
+		Run:          runView(gameSave),
+	}
+	if game.Over() {
+		mainData.Rating = gameSave.Rating
+	}
+	if gameSave.Coop {
+		player := playerName(w, req)
+		mainData.Coop = true
+		mainData.Contributors = view.FromContributions(gameSave.Contributors, game.Contributions(), player)
+		mainData.CanJoin = !game.Over() && !gameSave.PlayableBy(player)
+	}
+	if gameSave.Training {
+		mainData.Training = true
+		mainData.Mistakes = view.FromMistakes(*game, gameSave.Mistakes)
+		mainData.Retries = gameSave.Retries
+	}
+	if gameSave.Level != "" {
+		if pack, level, err := campaign.FindKey(gameSave.Level); err == nil {
+			mainData.Level = view.FromLevel(pack, level)
+		}
+	}
+	page := "game.html"
+	w.Header().Add("Vary", FragmentHeader)
+	if isFragmentRequest(req) {
+		page = "play.html"
+	}
+	executeTemplate(w, page, mainData)
+}
+
+// Writes a page from its template. Errors are logged rather than fatal, since they are most often the client going
+// away part way through the page.
+func executeTemplate(w http.ResponseWriter, name string, data any) {
+	if err := mainPageTemplate.ExecuteTemplate(w, name, data); err != nil {
+		log.Println("ExecuteTemplate:", err)
+	}
+}
+
+// Returns true if a request was made by the page's script for a fragment rather than by navigation.
+func isFragmentRequest(req *http.Request) bool {
+	return req.Header.Get(FragmentHeader) == "true"
+}
+
 func parseGenerationForm(req *http.Request) (int, int, int, error) {
-	switch difficulty := req.FormValue("difficulty"); difficulty {
-	case "beginner":
-		return 10, 8, 8, nil
-	case "intermediate":
-		return 40, 16, 16, nil
-	case "expert":
-		return 99, 30, 16, nil
+	difficulty := req.FormValue("difficulty")
+	if preset, ok := generation.Presets[difficulty]; ok {
+		return preset.Mines, preset.Width, preset.Height, nil
+	}
+	switch difficulty {
 	case "custom":
 		mines, err := strconv.Atoi(req.Form.Get("mines"))
 		if err != nil {
@@ -172,6 +605,59 @@ func parseGenerationForm(req *http.Request) (int, int, int, error) {
 	}
 }
 
+// Returns the name of the player making a request. Signed in players are known by their username and guests
+// are assigned a generated name by cookie. A cookie holding anything other than a generated name is replaced, so
+// a guest can never claim to be a registered player.
+func playerName(w http.ResponseWriter, req *http.Request) string {
+	if username, ok := signedIn(req); ok {
+		return username
+	}
+	if cookie, err := req.Cookie(PlayerCookie); err == nil && storage.ValidGuest(cookie.Value) {
+		return cookie.Value
+	}
+	player := generateName(rand.Int63())
+	cookie := &http.Cookie{
+		Name:     PlayerCookie,
+		Value:    player,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, cookie)
+	// Later lookups while handling this request must find the same name.
+	req.AddCookie(cookie)
+	return player
+}
+
+// Returns true if the player making a request owns a game.
+func ownsGame(w http.ResponseWriter, req *http.Request, gameSave *storage.GameSave) bool {
+	return gameSave.OwnedBy(playerName(w, req))
+}
+
+// Returns true if the player making a request may play a game.
+func canPlay(w http.ResponseWriter, req *http.Request, gameSave *storage.GameSave) bool {
+	return gameSave.PlayableBy(playerName(w, req))
+}
+
+// Returns true if the player making a request may watch a game, which is always true for its players.
+func canView(w http.ResponseWriter, req *http.Request, gameSave *storage.GameSave) bool {
+	return !gameSave.NoSpectators || canPlay(w, req, gameSave)
+}
+
+// Returns the flag state wanted by a flag click, or nil if the click should toggle the flag.
+func parseFlagged(req *http.Request) *bool {
+	switch req.FormValue("flagged") {
+	case "true":
+		flagged := true
+		return &flagged
+	case "false":
+		flagged := false
+		return &flagged
+	}
+	return nil
+}
+
 func generateName(seed int64) string {
 	buf := make([]byte, 8)
 	binary.LittleEndian.PutUint64(buf, uint64(seed))
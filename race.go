@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/live"
+	"github.com/deadly990/gominesweeper/race"
+	"github.com/deadly990/gominesweeper/solver"
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/websocket"
+)
+
+// RaceDifficulty is the difficulty race games are saved with, keeping them apart from solo games.
+const RaceDifficulty = "race"
+
+// finishedRaceLifetime is how long a finished race stays in memory for anyone still watching it.
+const finishedRaceLifetime = 10 * time.Minute
+
+var races = race.NewRegistry()
+
+var errRaceNotStarted = errors.New("the race has not started yet")
+
+func racesHandler(w http.ResponseWriter, req *http.Request) {
+	racesData := view.FromLobbies(races.Lobbies(time.Now()))
+	executeTemplate(w, "races.html", racesData)
+}
+
+// Opens a new race lobby on a random board of a preset difficulty, hosted by the player creating it.
+func createRaceHandler(w http.ResponseWriter, req *http.Request) {
+	difficulty := req.FormValue("difficulty")
+	if _, ok := generation.Presets[difficulty]; !ok {
+		http.Error(w, fmt.Sprintf("a valid difficulty was not sent: %s", difficulty), 400)
+		return
+	}
+	id := generateName(rand.Int63())
+	races.Create(id, difficulty, rand.Int63(), playerName(w, req))
+	http.Redirect(w, req, raceURL(id), http.StatusSeeOther)
+}
+
+// Displays a race's lobby, progress or results. Races no longer in memory are shown from their recorded results.
+func raceHandler(w http.ResponseWriter, req *http.Request) {
+	raceCtx := req.Context().Value(RaceIDString).(string)
+	player := playerName(w, req)
+	var raceData view.RaceData
+	if current, err := races.Find(raceCtx); err == nil {
+		raceData = view.FromRace(current.State(time.Now()), player)
+	} else {
+		results, err := database.RaceResults(raceCtx)
+		if err != nil || len(results) == 0 {
+			http.Error(w, "race not found", 404)
+			return
+		}
+		raceData = view.FromRaceResults(raceCtx, results, player)
+	}
+	page := "race.html"
+	if isFragmentRequest(req) {
+		page = "race_status.html"
+	}
+	executeTemplate(w, page, raceData)
+}
+
+func apiRaceHandler(w http.ResponseWriter, req *http.Request) {
+	raceCtx := req.Context().Value(RaceIDString).(string)
+	player := playerName(w, req)
+	current, err := races.Find(raceCtx)
+	if err != nil {
+		results, err := database.RaceResults(raceCtx)
+		if err != nil || len(results) == 0 {
+			writeJSONError(w, "race not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, view.ToRaceResultsJSON(results, player))
+		return
+	}
+	writeJSON(w, view.ToRaceJSON(current.State(time.Now()), player))
+}
+
+func joinRaceHandler(w http.ResponseWriter, req *http.Request) {
+	raceAction(w, req, func(current *race.Race, player string) error {
+		return current.Join(player)
+	})
+}
+
+func readyHandler(w http.ResponseWriter, req *http.Request) {
+	raceAction(w, req, func(current *race.Race, player string) error {
+		players, err := current.SetReady(player, req.FormValue("ready") == "true", time.Now())
+		if err == nil && players != nil {
+			startRace(current, players)
+		}
+		return err
+	})
+}
+
+func leaveRaceHandler(w http.ResponseWriter, req *http.Request) {
+	raceAction(w, req, func(current *race.Race, player string) error {
+		finished, err := current.Leave(player, time.Now())
+		if finished {
+			recordRace(current)
+		}
+		return err
+	})
+}
+
+// Applies a change by the requesting player to a race in memory, then returns them to the race page, or to the
+// list of races if the change closed its lobby.
+func raceAction(w http.ResponseWriter, req *http.Request, action func(*race.Race, string) error) {
+	raceCtx := req.Context().Value(RaceIDString).(string)
+	current, err := races.Find(raceCtx)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	if err := action(current, playerName(w, req)); err != nil {
+		http.Error(w, err.Error(), 409)
+		return
+	}
+	if _, err := races.Find(raceCtx); err != nil {
+		http.Redirect(w, req, "/race", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, req, raceURL(raceCtx), http.StatusSeeOther)
+}
+
+// Streams the state of a race over a WebSocket after every change.
+func raceLiveHandler(w http.ResponseWriter, req *http.Request) {
+	raceCtx := req.Context().Value(RaceIDString).(string)
+	current, err := races.Find(raceCtx)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	player := playerName(w, req)
+	server := websocket.Server{
+		Handshake: live.SameOrigin,
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			updates, stop := current.Subscribe()
+			defer stop()
+			go func() {
+				// Nothing is read from clients, but reading notices when they disconnect.
+				var discard []byte
+				for websocket.Message.Receive(conn, &discard) == nil {
+				}
+				stop()
+			}()
+			for state := range updates {
+				if websocket.JSON.Send(conn, view.ToRaceJSON(state, player)) != nil {
+					return
+				}
+			}
+		},
+	}
+	server.ServeHTTP(w, req)
+}
+
+// Creates every player's copy of the race board, each starting when the countdown ends, and ends the race for
+// anyone still racing once it has run for race.MaxDuration.
+func startRace(current *race.Race, players []string) {
+	state := current.State(time.Now())
+	deadline := time.UnixMilli(state.Start).Add(race.MaxDuration)
+	time.AfterFunc(time.Until(deadline), func() {
+		if current.Expire(time.Now()) {
+			recordRace(current)
+		}
+	})
+	preset := generation.Presets[state.Difficulty]
+	board, err := generation.NewBoard(preset.Mines, preset.Width, preset.Height, state.Seed)
+	if err != nil {
+		log.Println("NewBoard:", err)
+		return
+	}
+	rating := solver.Rate(*board, solver.DefaultStart(*board))
+	for _, player := range players {
+		gameName := generateName(rand.Int63())
+		gameSave := storage.FromGame(*game.NewGame(*board))
+		gameSave.Player = player
+		gameSave.Started = state.Start
+		gameSave.Difficulty = RaceDifficulty
+		gameSave.Race = state.ID
+		gameSave.Rating = &rating
+		saveGame(gameSave, gameName)
+		current.AssignGame(player, gameName)
+	}
+}
+
+// Reports a player's progress after a move in a race game, recording the results once everyone is done.
+func updateRace(gameSave *storage.GameSave, game *game.Game) {
+	current, err := races.Find(gameSave.Race)
+	if err != nil {
+		return
+	}
+	if current.Progress(gameSave.Player, game.Cleared(), game.Won(), game.Lost(), time.Now()) {
+		recordRace(current)
+	}
+}
+
+// Stores the ranked results of a finished race and forgets the race after a while.
+func recordRace(current *race.Race) {
+	state := current.State(time.Now())
+	// Players are only ranked in the state once the race has run, not when it ends during the countdown.
+	race.Rank(state.Players)
+	results := []storage.RaceResult{}
+	for index, player := range state.Players {
+		results = append(results, storage.RaceResult{
+			Race:       state.ID,
+			Player:     player.Name,
+			Rank:       index + 1,
+			Game:       player.Game,
+			Won:        player.Won,
+			DurationMs: player.Finished - state.Start,
+			Cleared:    player.Cleared,
+		})
+	}
+	if err := database.RecordRace(results); err != nil {
+		log.Println("RecordRace:", err)
+	}
+	time.AfterFunc(finishedRaceLifetime, func() { races.Remove(state.ID) })
+}
+
+func raceURL(id string) string {
+	return "/race/" + id
+}
+
+func RaceCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), RaceIDString, chi.URLParam(req, string(RaceIDString)))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,354 @@
+// Package race runs head-to-head races where every player clears their own copy of the same board.
+package race
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Countdown is the time between the last player readying up and the race starting.
+const Countdown = 5 * time.Second
+
+// MaxPlayers is the most players that can join one race.
+const MaxPlayers = 8
+
+// MaxDuration is how long a race runs before everyone still racing is out.
+const MaxDuration = 15 * time.Minute
+
+// Status is the stage a race is at.
+type Status string
+
+const (
+	Lobby    Status = "lobby"
+	Starting Status = "starting"
+	Running  Status = "running"
+	Finished Status = "finished"
+)
+
+var ErrNotFound = errors.New("race does not exist")
+var ErrStarted = errors.New("race has already started")
+var ErrFull = errors.New("race is full")
+var ErrNotJoined = errors.New("player has not joined this race")
+
+// Player is a racer and their progress.
+type Player struct {
+	// Name identifies the player to the server, so it is never sent to clients.
+	Name  string `json:"-"`
+	Ready bool   `json:"ready"`
+	// Game is the name of the player's game, set once the race starts.
+	Game string `json:"game,omitempty"`
+	// Cleared is the fraction of safe tiles the player has revealed.
+	Cleared float64 `json:"cleared"`
+	Alive   bool    `json:"alive"`
+	Won     bool    `json:"won"`
+	// Left is set when the player forfeited the race.
+	Left bool `json:"left,omitempty"`
+	// Finished is the Unix time in milliseconds the player won, lost or left, zero while racing.
+	Finished int64 `json:"finished,omitempty"`
+}
+
+// Done returns true once the player can make no more progress.
+func (player Player) Done() bool {
+	return player.Finished != 0
+}
+
+// State is a copy of a race at one moment, safe to read without locking.
+type State struct {
+	ID         string `json:"id"`
+	Difficulty string `json:"difficulty"`
+	// Seed decides the board everyone races on, so it is never sent to clients.
+	Seed   int64  `json:"-"`
+	Status Status `json:"status"`
+	// Start is the Unix time in milliseconds the race starts, zero while in the lobby.
+	Start   int64    `json:"start,omitempty"`
+	Players []Player `json:"players"`
+}
+
+// Race is a lobby of players and, once they are all ready, the race between them.
+type Race struct {
+	mutex sync.Mutex
+	// registry holds the race, which is removed from it once its lobby is empty.
+	registry    *Registry
+	id          string
+	difficulty  string
+	seed        int64
+	start       int64
+	players     []*Player
+	subscribers map[chan State]bool
+}
+
+// Registry holds the races in progress.
+type Registry struct {
+	mutex sync.Mutex
+	races map[string]*Race
+}
+
+func NewRegistry() *Registry {
+	return &Registry{races: map[string]*Race{}}
+}
+
+// Opens a lobby for a race on a seeded board of a difficulty, with its host as the first player.
+func (registry *Registry) Create(id string, difficulty string, seed int64, host string) *Race {
+	race := &Race{
+		registry:    registry,
+		id:          id,
+		difficulty:  difficulty,
+		seed:        seed,
+		players:     []*Player{{Name: host, Alive: true}},
+		subscribers: map[chan State]bool{},
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.races[id] = race
+	return race
+}
+
+func (registry *Registry) Find(id string) (*Race, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	race, ok := registry.races[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return race, nil
+}
+
+// Returns the state of every race still in its lobby.
+func (registry *Registry) Lobbies(now time.Time) []State {
+	registry.mutex.Lock()
+	races := []*Race{}
+	for _, race := range registry.races {
+		races = append(races, race)
+	}
+	registry.mutex.Unlock()
+	lobbies := []State{}
+	for _, race := range races {
+		if state := race.State(now); state.Status == Lobby {
+			lobbies = append(lobbies, state)
+		}
+	}
+	slices.SortFunc(lobbies, func(a State, b State) int { return cmp.Compare(a.ID, b.ID) })
+	return lobbies
+}
+
+// Forgets a race once its results have been recorded.
+func (registry *Registry) Remove(id string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	delete(registry.races, id)
+}
+
+// Adds a player to a race's lobby. Joining a race twice has no effect.
+func (race *Race) Join(name string) error {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	if race.player(name) != nil {
+		return nil
+	}
+	if race.start != 0 {
+		return ErrStarted
+	}
+	if len(race.players) >= MaxPlayers {
+		return ErrFull
+	}
+	race.players = append(race.players, &Player{Name: name, Alive: true})
+	race.broadcast()
+	return nil
+}
+
+// Removes a player from the lobby, closing it once everyone has left, or forfeits their race once it has started.
+// Returns true if leaving finished the race for everyone.
+func (race *Race) Leave(name string, now time.Time) (bool, error) {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	player := race.player(name)
+	if player == nil {
+		return false, ErrNotJoined
+	}
+	if race.start == 0 {
+		race.players = slices.DeleteFunc(race.players, func(other *Player) bool { return other == player })
+		race.broadcast()
+		if len(race.players) == 0 {
+			race.registry.Remove(race.id)
+		}
+		return false, nil
+	}
+	if player.Done() {
+		return false, nil
+	}
+	player.Alive = false
+	player.Left = true
+	player.Finished = now.UnixMilli()
+	race.broadcast()
+	return race.finished(), nil
+}
+
+// Marks a player as ready or not. Once at least two players have joined and all are ready the countdown
+// begins, and the players returned need a game each before it ends. Otherwise nil is returned.
+func (race *Race) SetReady(name string, ready bool, now time.Time) ([]string, error) {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	player := race.player(name)
+	if player == nil {
+		return nil, ErrNotJoined
+	}
+	if race.start != 0 {
+		return nil, ErrStarted
+	}
+	player.Ready = ready
+	defer race.broadcast()
+	if len(race.players) < 2 {
+		return nil, nil
+	}
+	names := []string{}
+	for _, other := range race.players {
+		if !other.Ready {
+			return nil, nil
+		}
+		names = append(names, other.Name)
+	}
+	race.start = now.Add(Countdown).UnixMilli()
+	return names, nil
+}
+
+// Records the game a player races on.
+func (race *Race) AssignGame(name string, game string) {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	if player := race.player(name); player != nil {
+		player.Game = game
+		race.broadcast()
+	}
+}
+
+// Updates a player's progress. Returns true if this update finished the race for everyone.
+func (race *Race) Progress(name string, cleared float64, won bool, lost bool, now time.Time) bool {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	player := race.player(name)
+	if player == nil || player.Done() {
+		return false
+	}
+	player.Cleared = cleared
+	player.Won = won
+	player.Alive = !lost
+	if won || lost {
+		player.Finished = now.UnixMilli()
+	}
+	race.broadcast()
+	return race.finished()
+}
+
+// Ends the race for everyone still racing once MaxDuration has passed since it started, leaving them out with what
+// they cleared. Returns true if this finished the race.
+func (race *Race) Expire(now time.Time) bool {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	deadline := race.start + MaxDuration.Milliseconds()
+	if race.start == 0 || now.UnixMilli() < deadline || race.finished() {
+		return false
+	}
+	for _, player := range race.players {
+		if !player.Done() {
+			player.Alive = false
+			player.Finished = deadline
+		}
+	}
+	race.broadcast()
+	return true
+}
+
+// Returns a copy of the race with its players ranked once it has finished.
+func (race *Race) State(now time.Time) State {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	return race.state(now)
+}
+
+// Returns a channel receiving the state of the race after every change, and the function to stop receiving.
+func (race *Race) Subscribe() (<-chan State, func()) {
+	race.mutex.Lock()
+	defer race.mutex.Unlock()
+	updates := make(chan State, 16)
+	race.subscribers[updates] = true
+	updates <- race.state(time.Now())
+	return updates, func() {
+		race.mutex.Lock()
+		defer race.mutex.Unlock()
+		if race.subscribers[updates] {
+			delete(race.subscribers, updates)
+			close(updates)
+		}
+	}
+}
+
+func (race *Race) state(now time.Time) State {
+	state := State{ID: race.id, Difficulty: race.difficulty, Seed: race.seed, Start: race.start, Players: []Player{}}
+	for _, player := range race.players {
+		state.Players = append(state.Players, *player)
+	}
+	switch {
+	case race.start == 0:
+		state.Status = Lobby
+	case now.UnixMilli() < race.start:
+		state.Status = Starting
+	case race.finished():
+		state.Status = Finished
+		Rank(state.Players)
+	default:
+		state.Status = Running
+	}
+	return state
+}
+
+// Sends the current state to every subscriber, skipping any that have fallen behind.
+// The race must be locked.
+func (race *Race) broadcast() {
+	state := race.state(time.Now())
+	for updates := range race.subscribers {
+		select {
+		case updates <- state:
+		default:
+		}
+	}
+}
+
+func (race *Race) finished() bool {
+	if race.start == 0 {
+		return false
+	}
+	for _, player := range race.players {
+		if !player.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+func (race *Race) player(name string) *Player {
+	for _, player := range race.players {
+		if player.Name == name {
+			return player
+		}
+	}
+	return nil
+}
+
+// Orders players by result. Winners come first by time, then everyone else by how much they cleared.
+func Rank(players []Player) {
+	slices.SortStableFunc(players, func(a Player, b Player) int {
+		if a.Won != b.Won {
+			if a.Won {
+				return -1
+			}
+			return 1
+		}
+		if a.Won {
+			return cmp.Compare(a.Finished, b.Finished)
+		}
+		return cmp.Compare(b.Cleared, a.Cleared)
+	})
+}
@@ -0,0 +1,148 @@
+package race
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadyStartsCountdown(test *testing.T) {
+	now := time.UnixMilli(1000000)
+	race := NewRegistry().Create("test", "beginner", 1, "alice")
+	if names, _ := race.SetReady("alice", true, now); names != nil {
+		log.Printf("Expected a race to need two players. Actual: %v", names)
+		test.Fail()
+	}
+	race.Join("bob")
+	if names, _ := race.SetReady("bob", true, now); len(names) != 2 {
+		log.Printf("Expected the countdown to start once everyone is ready. Actual: %v", names)
+		test.Fail()
+	}
+	if err := race.Join("carol"); !errors.Is(err, ErrStarted) {
+		log.Printf("Expected joining a started race to fail. Actual: %v", err)
+		test.Fail()
+	}
+	if status := race.State(now).Status; status != Starting {
+		log.Printf("Expected the race to be counting down. Actual: %s", status)
+		test.Fail()
+	}
+	if status := race.State(now.Add(Countdown)).Status; status != Running {
+		log.Printf("Expected the race to be running after the countdown. Actual: %s", status)
+		test.Fail()
+	}
+}
+
+func TestProgressFinishesRace(test *testing.T) {
+	now := time.UnixMilli(1000000)
+	race := NewRegistry().Create("test", "beginner", 1, "alice")
+	race.Join("bob")
+	race.Join("carol")
+	for _, name := range []string{"alice", "bob", "carol"} {
+		race.SetReady(name, true, now)
+	}
+	start := now.Add(Countdown)
+	updates, stop := race.Subscribe()
+	defer stop()
+	<-updates
+
+	race.Progress("alice", 0.5, false, true, start.Add(time.Second))
+	if state := <-updates; state.Players[0].Alive || state.Status != Running {
+		log.Printf("Expected alice to be out of a running race. Actual: %+v", state)
+		test.Fail()
+	}
+	race.Leave("carol", start.Add(2*time.Second))
+	if race.Progress("bob", 1, true, false, start.Add(3*time.Second)) != true {
+		log.Printf("Expected the race to finish once every player is done.")
+		test.Fail()
+	}
+	players := race.State(start.Add(4 * time.Second)).Players
+	if players[0].Name != "bob" || players[1].Name != "alice" || players[2].Name != "carol" {
+		log.Printf("Expected winners first and then the most cleared. Actual: %+v", players)
+		test.Fail()
+	}
+}
+
+func TestRank(test *testing.T) {
+	// Players in the order they joined.
+	players := []Player{
+		{Name: "alice", Cleared: 0.2, Finished: 1000},
+		{Name: "bob", Won: true, Cleared: 1, Finished: 3000},
+		{Name: "carol", Cleared: 0.6, Finished: 2000},
+		{Name: "dave", Won: true, Cleared: 1, Finished: 2500},
+	}
+	Rank(players)
+	order := []string{}
+	for _, player := range players {
+		order = append(order, player.Name)
+	}
+	if order[0] != "dave" || order[1] != "bob" || order[2] != "carol" || order[3] != "alice" {
+		log.Printf("Expected winners by time, then the rest by how much they cleared. Actual: %v", order)
+		test.Fail()
+	}
+}
+
+func TestStateHidesPlayerNames(test *testing.T) {
+	race := NewRegistry().Create("test", "beginner", 1, "alice-the-guest")
+	encoded, err := json.Marshal(race.State(time.UnixMilli(1000000)))
+	if err != nil {
+		log.Printf("Error encoding state: %s", err)
+		test.FailNow()
+	}
+	if strings.Contains(string(encoded), "alice-the-guest") {
+		log.Printf("Expected the encoded state not to name its players. Actual: %s", encoded)
+		test.Fail()
+	}
+}
+
+func TestExpireEndsRace(test *testing.T) {
+	now := time.UnixMilli(1000000)
+	race := NewRegistry().Create("test", "beginner", 1, "alice")
+	race.Join("bob")
+	race.SetReady("alice", true, now)
+	race.SetReady("bob", true, now)
+	start := now.Add(Countdown)
+	race.Progress("alice", 1, true, false, start.Add(time.Minute))
+
+	if race.Expire(start.Add(MaxDuration - time.Second)) {
+		log.Printf("Expected the race to keep running before its deadline.")
+		test.Fail()
+	}
+	if !race.Expire(start.Add(MaxDuration)) {
+		log.Printf("Expected the race to finish at its deadline.")
+		test.Fail()
+	}
+	state := race.State(start.Add(MaxDuration))
+	bob := state.Players[1]
+	if state.Status != Finished || bob.Alive || bob.Finished != start.Add(MaxDuration).UnixMilli() {
+		log.Printf("Expected bob to be out at the deadline. Actual: %+v", state)
+		test.Fail()
+	}
+	if race.Expire(start.Add(MaxDuration + time.Second)) {
+		log.Printf("Expected a finished race not to finish again.")
+		test.Fail()
+	}
+}
+
+func TestEmptyLobbyIsRemoved(test *testing.T) {
+	now := time.UnixMilli(1000000)
+	registry := NewRegistry()
+	race := registry.Create("test", "beginner", 1, "alice")
+	race.Join("bob")
+	race.Leave("alice", now)
+	if _, err := registry.Find("test"); err != nil {
+		log.Printf("Expected the lobby to stay open while a player is in it. Actual: %v", err)
+		test.Fail()
+	}
+	race.Leave("bob", now)
+	if _, err := registry.Find("test"); !errors.Is(err, ErrNotFound) {
+		log.Printf("Expected the lobby to close once everyone left. Actual: %v", err)
+		test.Fail()
+	}
+	if lobbies := registry.Lobbies(now); len(lobbies) != 0 {
+		log.Printf("Expected no lobbies to be listed. Actual: %+v", lobbies)
+		test.Fail()
+	}
+}
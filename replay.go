@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+)
+
+// Steps through the moves of a finished game. The step, speed and whether it is playing are kept in the query.
+func replayHandler(w http.ResponseWriter, req *http.Request) {
+	gameCtx := req.Context().Value(GameIDString).(string)
+	gameSave, err := storage.Load(gameCtx)
+	if err != nil {
+		http.Error(w, "game not found", 404)
+		return
+	}
+	if !canView(w, req, gameSave) {
+		http.Error(w, errNoSpectators.Error(), 403)
+		return
+	}
+	if gameSave.Finished == 0 {
+		http.Error(w, "replays are available once the game has finished", 409)
+		return
+	}
+	moves := gameSave.ToGame().Moves
+	step, err := strconv.Atoi(req.FormValue("step"))
+	if err != nil {
+		step = 0
+	}
+	step = max(0, min(step, len(moves)))
+	speed, err := strconv.ParseFloat(req.FormValue("speed"), 64)
+	if err != nil || !slices.Contains(view.ReplaySpeeds, speed) {
+		speed = 1
+	}
+	playing := req.FormValue("playing") == "true"
+
+	replayData := view.FromReplay(*gameSave.ReplayTo(step), moves, gameCtx, step, playing, speed)
+	executeTemplate(w, "replay.html", replayData)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/run"
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/view"
+	"github.com/go-chi/chi/v5"
+)
+
+// runLeaderboardSize is the number of runs shown on a run leaderboard.
+const runLeaderboardSize = 10
+
+var errRunOver = errors.New("the run is over")
+
+// Displays the runs that can be started and the leaderboard of a mode on a difficulty.
+func runsHandler(w http.ResponseWriter, req *http.Request) {
+	mode, err := run.ParseMode(req.FormValue("mode"))
+	if err != nil {
+		mode = run.Modes[0]
+	}
+	difficulty := req.FormValue("difficulty")
+	if _, ok := generation.Presets[difficulty]; !ok {
+		difficulty = generation.PresetNames[0]
+	}
+	renderRuns(w, req, mode, difficulty, nil)
+}
+
+// Starts a run of a mode on a preset difficulty and sends the player to its first board.
+func createRunHandler(w http.ResponseWriter, req *http.Request) {
+	mode, err := run.ParseMode(req.FormValue("mode"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	difficulty := req.FormValue("difficulty")
+	if _, ok := generation.Presets[difficulty]; !ok {
+		http.Error(w, fmt.Sprintf("a valid difficulty was not sent: %s", difficulty), 400)
+		return
+	}
+	now := time.Now()
+	current := &storage.Run{
+		Name:       generateName(rand.Int63()),
+		Player:     playerName(w, req),
+		Mode:       string(mode),
+		Difficulty: difficulty,
+		Seed:       rand.Int63(),
+		Started:    now.UnixMilli(),
+	}
+	if mode == run.TimeAttack {
+		current.Ends = now.Add(run.TimeLimit).UnixMilli()
+	}
+	if _, err := startStage(current, nil); err != nil {
+		log.Println("startStage:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	http.Redirect(w, req, "/game/"+current.Game, http.StatusSeeOther)
+}
+
+// Sends the player to the current board of a run, or shows the run's results once it is over.
+func runHandler(w http.ResponseWriter, req *http.Request) {
+	runCtx := req.Context().Value(RunIDString).(string)
+	current, err := loadRun(runCtx)
+	if err != nil {
+		http.Error(w, "run not found", 404)
+		return
+	}
+	if !current.Over(time.Now()) {
+		http.Redirect(w, req, "/game/"+current.Game, http.StatusSeeOther)
+		return
+	}
+	renderRuns(w, req, run.Mode(current.Mode), current.Difficulty, current)
+}
+
+func apiRunHandler(w http.ResponseWriter, req *http.Request) {
+	runCtx := req.Context().Value(RunIDString).(string)
+	current, err := loadRun(runCtx)
+	if err != nil {
+		writeJSONError(w, "run not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, current)
+}
+
+// Renders the runs page, with the results of a finished run if one is given.
+func renderRuns(w http.ResponseWriter, req *http.Request, mode run.Mode, difficulty string, finished *storage.Run) {
+	top, err := database.TopRuns(string(mode), difficulty, runLeaderboardSize, time.Now())
+	if err != nil {
+		log.Println("TopRuns:", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	runsData := view.FromRuns(mode, difficulty, top, playerName(w, req))
+	if finished != nil {
+		runsData.Run = view.FromRun(*finished, time.Now())
+	}
+	executeTemplate(w, "runs.html", runsData)
+}
+
+// Loads a run, recording its finish if its time has run out since it was last saved.
+func loadRun(name string) (*storage.Run, error) {
+	defer lockGame(name)()
+	current, err := database.LoadRun(name)
+	if err != nil {
+		return nil, err
+	}
+	if current.Finished == 0 && current.Over(time.Now()) {
+		current.Finished = current.Ends
+		if err := database.SaveRun(current); err != nil {
+			log.Println("SaveRun:", err)
+		}
+	}
+	return current, nil
+}
+
+// Saves the game of a run's current stage and the run pointing to it, returning the game. Endless stages after
+// the first continue from the board cleared before them.
+func startStage(current *storage.Run, previous *game.Game) (*game.Game, error) {
+	mode := run.Mode(current.Mode)
+	board, err := run.Board(mode, generation.Presets[current.Difficulty], current.Seed, current.Stage)
+	if err != nil {
+		return nil, err
+	}
+	stage := game.NewGame(*board)
+	if previous != nil && mode == run.Endless {
+		stage = run.Continue(*previous, *board)
+	}
+	gameSave := storage.FromGame(*stage)
+	if mode == run.Endless {
+		gameSave.Layout = board.Layout()
+	}
+	gameSave.Player = current.Player
+	gameSave.Started = time.Now().UnixMilli()
+	gameSave.Difficulty = current.Mode
+	gameSave.Run = current.Name
+	if stage.Won() {
+		// The blanks of the board before opened the whole new region.
+		gameSave.Finished = gameSave.Started
+		gameSave.Won = true
+	}
+	current.Game = generateName(rand.Int63())
+	saveGame(gameSave, current.Game)
+	return stage, database.SaveRun(current)
+}
+
+// Scores a finished stage of a run and starts the next one, unless the run has ended. Time attack runs move on
+// after every board, won or lost, and endless runs end with the first mine hit.
+func advanceRun(gameSave *storage.GameSave, played *game.Game, name string) {
+	defer lockGame(gameSave.Run)()
+	current, err := database.LoadRun(gameSave.Run)
+	if err != nil || current.Game != name || current.Finished != 0 {
+		return
+	}
+	now := time.Now()
+	if played.Won() && !current.Over(now) {
+		current.Score++
+		current.ScoredMs = now.UnixMilli() - current.Started
+	}
+	if current.Over(now) || (run.Mode(current.Mode) == run.Endless && !played.Won()) {
+		current.Finished = now.UnixMilli()
+		if current.Ends != 0 {
+			current.Finished = min(current.Finished, current.Ends)
+		}
+		if err := database.SaveRun(current); err != nil {
+			log.Println("SaveRun:", err)
+		}
+		return
+	}
+	for {
+		current.Stage++
+		played, err = startStage(current, played)
+		if err != nil {
+			log.Println("startStage:", err)
+			return
+		}
+		if !played.Won() {
+			return
+		}
+		current.Score++
+	}
+}
+
+// Returns an error if a game is a stage of a run that is over.
+func checkRun(gameSave *storage.GameSave) error {
+	if gameSave.Run == "" {
+		return nil
+	}
+	current, err := loadRun(gameSave.Run)
+	if err != nil {
+		return err
+	}
+	if current.Over(time.Now()) {
+		return errRunOver
+	}
+	return nil
+}
+
+// Returns the view of the run a game is a stage of, or nil if it is not part of one.
+func runView(gameSave *storage.GameSave) *view.RunView {
+	if gameSave.Run == "" {
+		return nil
+	}
+	current, err := loadRun(gameSave.Run)
+	if err != nil {
+		return nil
+	}
+	return view.FromRun(*current, time.Now())
+}
+
+func RunCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), RunIDString, chi.URLParam(req, string(RunIDString)))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,90 @@
+// Package run strings games together into runs scored as a whole. In time attack the player clears as many boards
+// as they can before the clock runs out, and in endless every board cleared grows a new region to clear below it.
+package run
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+// Mode is the kind of run being played.
+type Mode string
+
+const (
+	// TimeAttack runs last TimeLimit, moving to a new board whenever one is cleared or lost.
+	TimeAttack Mode = "timeattack"
+	// Endless runs last until a mine is hit, adding a region to the board whenever it is cleared.
+	Endless Mode = "endless"
+)
+
+// Modes are the modes runs can be played in, in the order they are offered.
+var Modes = []Mode{TimeAttack, Endless}
+
+// TimeLimit is the length of a time attack run.
+const TimeLimit = 3 * time.Minute
+
+// Returns the Mode with a name.
+func ParseMode(name string) (Mode, error) {
+	for _, mode := range Modes {
+		if string(mode) == name {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown run mode %q", name)
+}
+
+// Returns the name of a mode for players.
+func (mode Mode) Title() string {
+	if mode == Endless {
+		return "Endless"
+	}
+	return "Time attack"
+}
+
+// Returns the seed of a stage of a run. Each seed is drawn from the one before it, so a run's first seed
+// decides every board in it.
+func StageSeed(seed int64, stage int) int64 {
+	for ; stage > 0; stage-- {
+		seed = rand.New(rand.NewSource(seed)).Int63()
+	}
+	return seed
+}
+
+// Returns the board of a stage of a run on a preset. Time attack plays a new board each stage. Endless boards
+// are stage+1 regions the size of the preset, stacked from the top, each holding the preset's mines.
+func Board(mode Mode, preset generation.Preset, seed int64, stage int) (*generation.Board, error) {
+	if mode == TimeAttack {
+		return generation.NewBoard(preset.Mines, preset.Width, preset.Height, StageSeed(seed, stage))
+	}
+	layout := []string{}
+	for region := 0; region <= stage; region++ {
+		board, err := generation.NewBoard(preset.Mines, preset.Width, preset.Height, StageSeed(seed, region))
+		if err != nil {
+			return nil, err
+		}
+		layout = append(layout, board.Layout()...)
+	}
+	return generation.FromLayout(layout)
+}
+
+// Returns a game on the grown board of the next endless stage, with every tile of the cleared board before it
+// revealed again and every mine in it flagged. Numbers along the new region may have grown, and blanks along it
+// may open into it.
+func Continue(previous game.Game, board generation.Board) *game.Game {
+	next := game.NewGame(board)
+	for y, row := range previous.Board.Field {
+		for x, value := range row {
+			coord := game.Coordinate{X: x, Y: y}
+			if value == -9 {
+				next.Play(game.Move{Coordinate: coord, Action: game.Flag})
+			} else if next.Revealed[y][x] < 0 {
+				next.Play(game.Move{Coordinate: coord, Action: game.Reveal})
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,88 @@
+package run
+
+import (
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+func TestStageSeed(test *testing.T) {
+	if StageSeed(42, 0) != 42 {
+		log.Printf("Expected the first stage to use the run's seed.")
+		test.Fail()
+	}
+	if StageSeed(42, 3) != StageSeed(StageSeed(42, 1), 2) || StageSeed(42, 1) == StageSeed(42, 2) {
+		log.Printf("Expected every stage seed to be drawn from the one before it.")
+		test.Fail()
+	}
+}
+
+func TestEndlessBoardGrows(test *testing.T) {
+	preset := generation.Presets["beginner"]
+	first, err := Board(Endless, preset, 7, 0)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	third, err := Board(Endless, preset, 7, 2)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	width, height := third.BoardSize()
+	if width != preset.Width || height != 3*preset.Height || third.Mines != 3*preset.Mines {
+		log.Printf("Expected three regions of the preset. Actual: %dx%d with %d mines", width, height, third.Mines)
+		test.Fail()
+	}
+	if !reflect.DeepEqual(third.Layout()[:preset.Height], first.Layout()) {
+		log.Printf("Expected the first region to stay the same as the board grows.")
+		test.Fail()
+	}
+}
+
+func TestContinue(test *testing.T) {
+	preset := generation.Presets["beginner"]
+	first, err := Board(Endless, preset, 7, 0)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	cleared := game.NewGame(*first)
+	for y, row := range first.Field {
+		for x, value := range row {
+			if value != -9 {
+				cleared.Play(game.Move{Coordinate: game.Coordinate{X: x, Y: y}})
+			}
+		}
+	}
+	if !cleared.Won() {
+		log.Printf("Expected revealing every safe tile to clear the board.")
+		test.FailNow()
+	}
+	grown, err := Board(Endless, preset, 7, 1)
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	next := Continue(*cleared, *grown)
+	for y, row := range first.Field {
+		for x, value := range row {
+			coord := game.Coordinate{X: x, Y: y}
+			if value == -9 && !next.Flagged(coord) {
+				log.Printf("Expected the mine at %d_%d to be flagged.", y, x)
+				test.Fail()
+			}
+			if value != -9 && next.Revealed[y][x] < 0 {
+				log.Printf("Expected the tile at %d_%d to stay revealed.", y, x)
+				test.Fail()
+			}
+		}
+	}
+	if next.Over() {
+		log.Printf("Expected the new region to be left to clear.")
+		test.Fail()
+	}
+}
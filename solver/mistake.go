@@ -0,0 +1,52 @@
+package solver
+
+import "github.com/deadly990/gominesweeper/game"
+
+// Mistake is a reveal that deduction from the visible board could have warned against: the tile could have been
+// proven to be a mine, or it was a guess while another tile could have been proven safe.
+type Mistake struct {
+	// Move is the index of the reveal among the game's moves, and Coordinate the tile it revealed.
+	Move       int             `json:"move"`
+	Coordinate game.Coordinate `json:"coordinate"`
+	// Mine is true when the revealed tile was proven to be a mine. Otherwise the reveal was a guess and Safe is
+	// a tile that was proven safe instead.
+	Mine bool             `json:"mine"`
+	Safe *game.Coordinate `json:"safe,omitempty"`
+	// Technique is the easiest technique that makes the missed deduction, and Reasons the revealed numbers it
+	// follows from.
+	Technique Technique         `json:"technique"`
+	Reasons   []game.Coordinate `json:"reasons,omitempty"`
+}
+
+// Reviews revealing a tile of a State, returning the Mistake it would be or nil if it is a sound reveal. Reveals
+// of tiles proven safe are sound, as are guesses when nothing could be proven safe.
+func Review(state State, coord game.Coordinate) *Mistake {
+	if !state.inRange(coord) || state.value(coord) != Hidden {
+		return nil
+	}
+	constraints := state.constraints()
+	techniques := []struct {
+		technique Technique
+		deduce    func() []Deduction
+	}{
+		{Single, func() []Deduction { return single(constraints) }},
+		{Subset, func() []Deduction { return subset(constraints) }},
+		{Enumeration, func() []Deduction { return enumeration(state) }},
+	}
+	var missed *Mistake
+	for _, current := range techniques {
+		for _, deduction := range current.deduce() {
+			if deduction.Coordinate == coord {
+				if !deduction.Mine {
+					return nil
+				}
+				return &Mistake{Coordinate: coord, Mine: true, Technique: current.technique, Reasons: deduction.Reasons}
+			}
+			if missed == nil && !deduction.Mine {
+				safe := deduction.Coordinate
+				missed = &Mistake{Coordinate: coord, Safe: &safe, Technique: current.technique, Reasons: deduction.Reasons}
+			}
+		}
+	}
+	return missed
+}
@@ -0,0 +1,125 @@
+package solver
+
+import (
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+// Rating describes how difficult a board is to clear from a start tile.
+type Rating struct {
+	// ThreeBV is the minimum number of clicks needed to clear the board without flagging.
+	ThreeBV  int `json:"3bv"`
+	Openings int `json:"openings"`
+	// Guesses counts the times no tile could be proven safe while solving.
+	Guesses int       `json:"guesses"`
+	Hardest Technique `json:"hardest"`
+	Score   float64   `json:"score"`
+}
+
+var techniqueWeight = map[Technique]float64{
+	None:        1,
+	Single:      1,
+	Subset:      1.25,
+	Enumeration: 1.5,
+}
+
+// Rates a board by solving it from a start tile. The solver reveals every tile it can prove safe using the
+// easiest technique available and, when it is stuck, counts a guess and reveals the safe tile it thought least
+// likely to be a mine. The score is the 3BV scaled by the hardest technique, plus 10 for every guess.
+func Rate(board generation.Board, start game.Coordinate) Rating {
+	rating := Rating{ThreeBV: board.ThreeBV(), Openings: board.Openings()}
+	if !board.IsInRange(start.Y, start.X) || board.Field[start.Y][start.X] == -9 {
+		start = DefaultStart(board)
+	}
+
+	current := game.NewGame(board)
+	current.Clear(start)
+	flagged := map[game.Coordinate]bool{}
+	for !current.Over() {
+		state := FromGame(*current)
+		for coord := range flagged {
+			state.Flag(coord)
+		}
+		deductions, technique := Deduce(state)
+		if technique == Guess {
+			rating.Guesses++
+			current.Clear(safestGuess(board, state))
+			continue
+		}
+		rating.Hardest = max(rating.Hardest, technique)
+		for _, deduction := range deductions {
+			if deduction.Mine {
+				flagged[deduction.Coordinate] = true
+			} else {
+				current.Clear(deduction.Coordinate)
+			}
+		}
+	}
+	rating.Score = float64(rating.ThreeBV)*techniqueWeight[rating.Hardest] + 10*float64(rating.Guesses)
+	return rating
+}
+
+// Returns the safe Hidden tile with the lowest chance of being a mine, preferring blank tiles.
+func safestGuess(board generation.Board, state State) game.Coordinate {
+	probability := Analyze(state).Probability
+	best := game.Coordinate{}
+	found := false
+	for _, coord := range state.HiddenTiles() {
+		value := board.Field[coord.Y][coord.X]
+		if value == -9 {
+			continue
+		}
+		if !found || probability[coord] < probability[best] ||
+			(probability[coord] == probability[best] && value == 0 && board.Field[best.Y][best.X] != 0) {
+			best, found = coord, true
+		}
+	}
+	return best
+}
+
+// Returns the first tile of the largest opening, or the first safe tile if the board has no openings.
+func DefaultStart(board generation.Board) game.Coordinate {
+	width, height := board.BoardSize()
+	visited := make([][]bool, height)
+	for y := range visited {
+		visited[y] = make([]bool, width)
+	}
+	best, bestSize := game.Coordinate{}, 0
+	for y, row := range board.Field {
+		for x, value := range row {
+			if value == -9 || visited[y][x] {
+				continue
+			}
+			size := 1
+			if value == 0 {
+				size = openingSize(board, game.Coordinate{X: x, Y: y}, visited)
+			}
+			if size > bestSize {
+				best, bestSize = game.Coordinate{X: x, Y: y}, size
+			}
+		}
+	}
+	return best
+}
+
+// Returns the number of tiles revealed by clicking a blank tile, marking the blank tiles of its opening as visited.
+func openingSize(board generation.Board, start game.Coordinate, visited [][]bool) int {
+	grid := board.Grid()
+	seen := map[game.Coordinate]bool{start: true}
+	queue := []game.Coordinate{start}
+	for len(queue) > 0 {
+		coord := queue[0]
+		queue = queue[1:]
+		if board.Field[coord.Y][coord.X] != 0 {
+			continue
+		}
+		visited[coord.Y][coord.X] = true
+		for _, adjacent := range coord.Adjacent(grid) {
+			if !seen[adjacent] {
+				seen[adjacent] = true
+				queue = append(queue, adjacent)
+			}
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,623 @@
+package solver
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+const (
+	// Hidden marks a tile whose value is not known to the player.
+	Hidden = -1
+	// Flagged marks a hidden tile that is known to be a mine.
+	Flagged = -2
+	// Exploded marks a revealed mine.
+	Exploded = game.Exploded
+)
+
+// nodeBudget limits the number of assignments tried when enumerating a single frontier component.
+const nodeBudget = 500000
+
+// Technique is a class of deduction, ordered from easiest to hardest.
+type Technique int
+
+const (
+	// None means the board was solved without any deduction, by openings alone.
+	None Technique = iota
+	// Single deductions use one number: it already touches all its mines, or all its hidden tiles are mines.
+	Single
+	// Subset deductions compare two overlapping numbers, as in the 1-1 and 1-2 patterns.
+	Subset
+	// Enumeration deductions hold in every arrangement of mines consistent with the board and remaining mine count.
+	Enumeration
+	// Guess means no deduction was possible.
+	Guess
+)
+
+var techniqueNames = []string{"none", "single", "subset", "enumeration", "guess"}
+
+func (technique Technique) String() string {
+	if technique < None || technique > Guess {
+		return fmt.Sprintf("Technique(%d)", int(technique))
+	}
+	return techniqueNames[technique]
+}
+
+func (technique Technique) MarshalJSON() ([]byte, error) {
+	return json.Marshal(technique.String())
+}
+
+func (technique *Technique) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for index, known := range techniqueNames {
+		if known == name {
+			*technique = Technique(index)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown technique: %q", name)
+}
+
+// State is the knowledge a player has of a board.
+type State struct {
+	// Field holds revealed numbers, Hidden, Flagged and Exploded tiles.
+	Field [][]int
+	// Mines is the total number of mines on the board.
+	Mines int
+	// Topology and Neighborhood decide which tiles neighbor each other.
+	Topology     generation.Topology
+	Neighborhood generation.Neighborhood
+	// Layers is the number of layers on three dimensional boards, zero otherwise.
+	Layers int
+}
+
+// Deduction proves whether a hidden tile is a mine.
+type Deduction struct {
+	Coordinate game.Coordinate
+	Mine       bool
+	// Reasons are the revealed numbers the deduction follows from, empty for deductions by enumeration.
+	Reasons []game.Coordinate
+}
+
+// Returns the State visible to the player of a Game. Revealed tiles keep their value and every other tile is Hidden.
+func FromGame(g game.Game) State {
+	field := make([][]int, len(g.Revealed))
+	for y, row := range g.Revealed {
+		field[y] = make([]int, len(row))
+		for x, value := range row {
+			field[y][x] = Hidden
+			if value >= 0 {
+				field[y][x] = value
+			}
+		}
+	}
+	return State{field, g.Board.Mines, g.Board.Topology, g.Board.Neighborhood, g.Board.Layers}
+}
+
+// Returns the width and height of the state's field.
+func (state State) size() (int, int) {
+	return len(state.Field[0]), len(state.Field)
+}
+
+func (state State) inRange(coord game.Coordinate) bool {
+	width, height := state.size()
+	return coord.X >= 0 && coord.X < width && coord.Y >= 0 && coord.Y < height
+}
+
+func (state State) value(coord game.Coordinate) int {
+	return state.Field[coord.Y][coord.X]
+}
+
+// Returns the neighbors of a tile that are on the board, excluding the tile itself.
+func (state State) neighbors(coord game.Coordinate) []game.Coordinate {
+	width, height := state.size()
+	return coord.Adjacent(generation.Grid{Width: width, Height: height, Topology: state.Topology, Neighborhood: state.Neighborhood, Layers: state.Layers})
+}
+
+// Marks a tile as a known mine.
+func (state State) Flag(coord game.Coordinate) {
+	state.Field[coord.Y][coord.X] = Flagged
+}
+
+// Returns every Hidden tile.
+func (state State) HiddenTiles() []game.Coordinate {
+	hidden := []game.Coordinate{}
+	for y, row := range state.Field {
+		for x, value := range row {
+			if value == Hidden {
+				hidden = append(hidden, game.Coordinate{X: x, Y: y})
+			}
+		}
+	}
+	return hidden
+}
+
+// Returns the number of mines not yet flagged or exploded.
+func (state State) remainingMines() int {
+	remaining := state.Mines
+	for _, row := range state.Field {
+		for _, value := range row {
+			if value == Flagged || value == Exploded {
+				remaining--
+			}
+		}
+	}
+	return remaining
+}
+
+// constraint states that exactly mines of the cells are mines, as the number at origin shows.
+type constraint struct {
+	cells  []game.Coordinate
+	mines  int
+	origin game.Coordinate
+}
+
+// Returns a constraint for every revealed number that touches a Hidden tile.
+func (state State) constraints() []constraint {
+	constraints := []constraint{}
+	for y, row := range state.Field {
+		for x, value := range row {
+			if value < 0 || value == Exploded {
+				continue
+			}
+			coord := game.Coordinate{X: x, Y: y}
+			current := constraint{[]game.Coordinate{}, value, coord}
+			for _, neighbor := range state.neighbors(coord) {
+				switch state.value(neighbor) {
+				case Hidden:
+					current.cells = append(current.cells, neighbor)
+				case Flagged, Exploded:
+					current.mines--
+				}
+			}
+			if len(current.cells) > 0 {
+				constraints = append(constraints, current)
+			}
+		}
+	}
+	return constraints
+}
+
+// Returns the deductions that can be made with the easiest technique that makes any, along with that technique.
+// Guess is returned with no deductions when nothing can be proven.
+func Deduce(state State) ([]Deduction, Technique) {
+	constraints := state.constraints()
+	if deductions := single(constraints); len(deductions) > 0 {
+		return deductions, Single
+	}
+	if deductions := subset(constraints); len(deductions) > 0 {
+		return deductions, Subset
+	}
+	if deductions := enumeration(state); len(deductions) > 0 {
+		return deductions, Enumeration
+	}
+	return []Deduction{}, Guess
+}
+
+func single(constraints []constraint) []Deduction {
+	found := map[game.Coordinate]bool{}
+	deductions := []Deduction{}
+	for _, current := range constraints {
+		if current.mines != 0 && current.mines != len(current.cells) {
+			continue
+		}
+		for _, cell := range current.cells {
+			if _, ok := found[cell]; !ok {
+				found[cell] = current.mines != 0
+				deductions = append(deductions, Deduction{cell, current.mines != 0, []game.Coordinate{current.origin}})
+			}
+		}
+	}
+	return deductions
+}
+
+// Compares every pair of overlapping constraints. The shared tiles can hold only so many of either
+// constraint's mines, which can force the tiles that only one of the pair touches.
+func subset(constraints []constraint) []Deduction {
+	found := map[game.Coordinate]bool{}
+	deductions := []Deduction{}
+	add := func(cells []game.Coordinate, mine bool, reasons []game.Coordinate) {
+		for _, cell := range cells {
+			if _, ok := found[cell]; !ok {
+				found[cell] = mine
+				deductions = append(deductions, Deduction{cell, mine, reasons})
+			}
+		}
+	}
+	touching := map[game.Coordinate][]int{}
+	for index, current := range constraints {
+		for _, cell := range current.cells {
+			touching[cell] = append(touching[cell], index)
+		}
+	}
+	for i, first := range constraints {
+		compared := map[int]bool{i: true}
+		for _, cell := range first.cells {
+			for _, j := range touching[cell] {
+				if compared[j] {
+					continue
+				}
+				compared[j] = true
+				second := constraints[j]
+				shared, onlyFirst, onlySecond := partition(first.cells, second.cells)
+				// The shared tiles hold at most maxShared mines, so the first constraint's own tiles hold the rest.
+				maxShared := min(shared, first.mines, second.mines)
+				if len(onlyFirst) > 0 && first.mines-maxShared == len(onlyFirst) {
+					add(onlyFirst, true, []game.Coordinate{first.origin, second.origin})
+				}
+				// The shared tiles hold at least minShared mines; if that satisfies the second constraint, its own tiles are safe.
+				minShared := first.mines - len(onlyFirst)
+				if len(onlySecond) > 0 && second.mines-minShared == 0 {
+					add(onlySecond, false, []game.Coordinate{first.origin, second.origin})
+				}
+			}
+		}
+	}
+	return deductions
+}
+
+// Returns the deductions that hold in every arrangement of mines consistent with a State.
+func enumeration(state State) []Deduction {
+	analysis := Analyze(state)
+	deductions := []Deduction{}
+	for _, coord := range analysis.Safe {
+		deductions = append(deductions, Deduction{coord, false, nil})
+	}
+	for _, coord := range analysis.Mines {
+		deductions = append(deductions, Deduction{coord, true, nil})
+	}
+	return deductions
+}
+
+// Returns the number of cells in both slices and the cells in only one of them.
+func partition(first []game.Coordinate, second []game.Coordinate) (int, []game.Coordinate, []game.Coordinate) {
+	inSecond := map[game.Coordinate]bool{}
+	for _, cell := range second {
+		inSecond[cell] = true
+	}
+	shared := 0
+	onlyFirst := []game.Coordinate{}
+	inFirst := map[game.Coordinate]bool{}
+	for _, cell := range first {
+		inFirst[cell] = true
+		if inSecond[cell] {
+			shared++
+		} else {
+			onlyFirst = append(onlyFirst, cell)
+		}
+	}
+	onlySecond := []game.Coordinate{}
+	for _, cell := range second {
+		if !inFirst[cell] {
+			onlySecond = append(onlySecond, cell)
+		}
+	}
+	return shared, onlyFirst, onlySecond
+}
+
+// Analysis holds the results of enumerating every arrangement of mines consistent with a State.
+type Analysis struct {
+	// Safe and Mines are the Hidden tiles proven safe or proven to be mines.
+	Safe  []game.Coordinate
+	Mines []game.Coordinate
+	// Probability is the chance each Hidden tile is a mine.
+	Probability map[game.Coordinate]float64
+}
+
+// component is a group of frontier tiles linked by shared constraints, with the number of arrangements of
+// its mines by total mine count.
+type component struct {
+	cells    []game.Coordinate
+	counts   []float64
+	cellMine [][]float64 // cellMine[total][cell] counts the arrangements with that many mines where the cell is a mine.
+	solved   bool
+}
+
+// Enumerates the arrangements of mines consistent with a State, including the remaining mine count.
+// Frontier components too large to enumerate are given the average mine density and prove nothing.
+func Analyze(state State) Analysis {
+	analysis := Analysis{[]game.Coordinate{}, []game.Coordinate{}, map[game.Coordinate]float64{}}
+	hidden := state.HiddenTiles()
+	if len(hidden) == 0 {
+		return analysis
+	}
+	constraints := state.constraints()
+	components := split(constraints)
+	for index := range components {
+		components[index].enumerate(constraints)
+	}
+
+	inFrontier := map[game.Coordinate]bool{}
+	for _, current := range components {
+		for _, cell := range current.cells {
+			inFrontier[cell] = true
+		}
+	}
+	rest := []game.Coordinate{}
+	for _, cell := range hidden {
+		if !inFrontier[cell] {
+			rest = append(rest, cell)
+		}
+	}
+	remaining := state.remainingMines()
+
+	solved := []int{}
+	unsolvedCells := 0
+	for index, current := range components {
+		if current.solved {
+			solved = append(solved, index)
+		} else {
+			unsolvedCells += len(current.cells)
+		}
+	}
+	density := float64(remaining) / float64(len(hidden))
+	if unsolvedCells > 0 {
+		// Without every component the remaining mine count cannot be applied, so each solved component stands alone.
+		for _, index := range solved {
+			components[index].standalone(&analysis)
+		}
+		for _, current := range components {
+			if !current.solved {
+				for _, cell := range current.cells {
+					analysis.Probability[cell] = density
+				}
+			}
+		}
+		for _, cell := range rest {
+			analysis.Probability[cell] = density
+		}
+		analysis.sort()
+		return analysis
+	}
+
+	// weight returns the number of ways to place the mines left over from the frontier in the rest of the board.
+	weight := func(frontierMines int) float64 {
+		return binomial(len(rest), remaining-frontierMines)
+	}
+	others := func(skip int) []float64 {
+		combined := []float64{1}
+		for index, current := range components {
+			if index != skip {
+				combined = convolve(combined, current.counts)
+			}
+		}
+		return combined
+	}
+
+	all := others(-1)
+	total := 0.0
+	restMines := 0.0
+	for frontierMines, count := range all {
+		ways := count * weight(frontierMines)
+		total += ways
+		if len(rest) > 0 {
+			restMines += ways * float64(remaining-frontierMines) / float64(len(rest))
+		}
+	}
+	if total == 0 {
+		// The state is inconsistent, such as after flagging a safe tile.
+		for _, cell := range hidden {
+			analysis.Probability[cell] = density
+		}
+		return analysis
+	}
+
+	for index, current := range components {
+		without := others(index)
+		for cell := range current.cells {
+			mineWays, safeWays := 0.0, 0.0
+			for componentMines, count := range current.counts {
+				if count == 0 {
+					continue
+				}
+				for otherMines, otherCount := range without {
+					ways := otherCount * weight(componentMines+otherMines)
+					mineWays += current.cellMine[componentMines][cell] * ways
+					safeWays += (count - current.cellMine[componentMines][cell]) * ways
+				}
+			}
+			analysis.classify(current.cells[cell], mineWays, safeWays, total)
+		}
+	}
+	for _, cell := range rest {
+		analysis.classify(cell, restMines, total-restMines, total)
+	}
+	analysis.sort()
+	return analysis
+}
+
+func (analysis *Analysis) classify(cell game.Coordinate, mineWays float64, safeWays float64, total float64) {
+	analysis.Probability[cell] = mineWays / total
+	if mineWays == 0 {
+		analysis.Safe = append(analysis.Safe, cell)
+		analysis.Probability[cell] = 0
+	} else if safeWays <= 0 {
+		analysis.Mines = append(analysis.Mines, cell)
+		analysis.Probability[cell] = 1
+	}
+}
+
+// Records the deductions and probabilities of a component without the remaining mine count.
+func (current component) standalone(analysis *Analysis) {
+	total := 0.0
+	for _, count := range current.counts {
+		total += count
+	}
+	for cell, coord := range current.cells {
+		mineWays := 0.0
+		for componentMines := range current.counts {
+			mineWays += current.cellMine[componentMines][cell]
+		}
+		analysis.classify(coord, mineWays, total-mineWays, total)
+	}
+}
+
+func (analysis *Analysis) sort() {
+	less := func(coords []game.Coordinate) func(int, int) bool {
+		return func(i, j int) bool {
+			if coords[i].Y != coords[j].Y {
+				return coords[i].Y < coords[j].Y
+			}
+			return coords[i].X < coords[j].X
+		}
+	}
+	sort.Slice(analysis.Safe, less(analysis.Safe))
+	sort.Slice(analysis.Mines, less(analysis.Mines))
+}
+
+// Groups the cells of constraints into components of cells that share constraints.
+func split(constraints []constraint) []component {
+	parent := map[game.Coordinate]game.Coordinate{}
+	var find func(game.Coordinate) game.Coordinate
+	find = func(cell game.Coordinate) game.Coordinate {
+		if parent[cell] != cell {
+			parent[cell] = find(parent[cell])
+		}
+		return parent[cell]
+	}
+	order := []game.Coordinate{}
+	for _, current := range constraints {
+		for _, cell := range current.cells {
+			if _, ok := parent[cell]; !ok {
+				parent[cell] = cell
+				order = append(order, cell)
+			}
+		}
+		for _, cell := range current.cells[1:] {
+			parent[find(cell)] = find(current.cells[0])
+		}
+	}
+	groups := map[game.Coordinate]int{}
+	components := []component{}
+	for _, cell := range order {
+		root := find(cell)
+		index, ok := groups[root]
+		if !ok {
+			index = len(components)
+			groups[root] = index
+			components = append(components, component{})
+		}
+		components[index].cells = append(components[index].cells, cell)
+	}
+	return components
+}
+
+// Counts every arrangement of mines in the component that satisfies the constraints touching it.
+func (current *component) enumerate(constraints []constraint) {
+	index := map[game.Coordinate]int{}
+	for position, cell := range current.cells {
+		index[cell] = position
+	}
+	// Constraints on this component, as cell positions, and the constraints each cell belongs to.
+	type local struct {
+		cells      []int
+		mines      int
+		assigned   int // Cells of the constraint assigned so far.
+		minesSoFar int
+	}
+	locals := []*local{}
+	membership := make([][]*local, len(current.cells))
+	for _, each := range constraints {
+		if _, ok := index[each.cells[0]]; !ok {
+			continue
+		}
+		converted := &local{mines: each.mines}
+		for _, cell := range each.cells {
+			converted.cells = append(converted.cells, index[cell])
+			membership[index[cell]] = append(membership[index[cell]], converted)
+		}
+		locals = append(locals, converted)
+	}
+
+	current.counts = make([]float64, len(current.cells)+1)
+	current.cellMine = make([][]float64, len(current.cells)+1)
+	for total := range current.cellMine {
+		current.cellMine[total] = make([]float64, len(current.cells))
+	}
+	assignment := make([]bool, len(current.cells))
+	nodes := 0
+	var search func(position int, mines int) bool
+	search = func(position int, mines int) bool {
+		nodes++
+		if nodes > nodeBudget {
+			return false
+		}
+		if position == len(current.cells) {
+			current.counts[mines]++
+			for cell, mine := range assignment {
+				if mine {
+					current.cellMine[mines][cell]++
+				}
+			}
+			return true
+		}
+		for _, mine := range []bool{false, true} {
+			valid := true
+			for _, each := range membership[position] {
+				each.assigned++
+				if mine {
+					each.minesSoFar++
+				}
+				unassigned := len(each.cells) - each.assigned
+				if each.minesSoFar > each.mines || each.minesSoFar+unassigned < each.mines {
+					valid = false
+				}
+			}
+			assignment[position] = mine
+			next := mines
+			if mine {
+				next++
+			}
+			completed := true
+			if valid {
+				completed = search(position+1, next)
+			}
+			for _, each := range membership[position] {
+				each.assigned--
+				if mine {
+					each.minesSoFar--
+				}
+			}
+			assignment[position] = false
+			if !completed {
+				return false
+			}
+		}
+		return true
+	}
+	current.solved = search(0, 0)
+}
+
+func convolve(first []float64, second []float64) []float64 {
+	result := make([]float64, len(first)+len(second)-1)
+	for i, a := range first {
+		if a == 0 {
+			continue
+		}
+		for j, b := range second {
+			result[i+j] += a * b
+		}
+	}
+	return result
+}
+
+// Returns n choose k, or zero when k is out of range.
+func binomial(n int, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	if k > n-k {
+		k = n - k
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package solver
+
+import (
+	"log"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+func hasDeduction(deductions []Deduction, coord game.Coordinate, mine bool) bool {
+	for _, deduction := range deductions {
+		if deduction.Coordinate == coord && deduction.Mine == mine {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeduceSingle(test *testing.T) {
+	state := State{Field: [][]int{
+		{0, 1, -1}, // [0, 1, ?]
+		{0, 1, -1}, // [0, 1, ?]
+		{0, 1, 1},  // [0, 1, 1]
+	}, Mines: 1}
+
+	deductions, technique := Deduce(state)
+	if technique != Single || !hasDeduction(deductions, game.Coordinate{X: 2, Y: 1}, true) {
+		log.Printf("Expected a single deduction of the mine at 1_2. Actual: %v %+v", technique, deductions)
+		test.Fail()
+	}
+}
+
+func TestDeduceSubset(test *testing.T) {
+	// A 1-2 pattern along the bottom wall: the 2 has one more hidden neighbor than the 1, which must be a mine.
+	state := State{Field: [][]int{
+		{0, 0, 0, 0},     // [0, 0, 0, 0]
+		{1, 1, 2, 1},     // [1, 1, 2, 1]
+		{-1, -1, -1, -1}, // [?, ?, ?, ?]
+	}, Mines: 2}
+
+	deductions, technique := Deduce(state)
+	if technique != Subset {
+		log.Printf("Expected the subset technique. Actual: %v %+v", technique, deductions)
+		test.FailNow()
+	}
+	if !hasDeduction(deductions, game.Coordinate{X: 3, Y: 2}, true) || !hasDeduction(deductions, game.Coordinate{X: 0, Y: 2}, false) {
+		log.Printf("Expected 2_3 to be a mine and 2_0 to be safe. Actual: %+v", deductions)
+		test.Fail()
+	}
+}
+
+func TestDeduceMineCount(test *testing.T) {
+	// The 1 cannot tell which of its neighbors is the mine, but the mine count proves the far column safe.
+	state := State{Field: [][]int{
+		{1, -1, -1},  // [1, ?, ?]
+		{-1, -1, -1}, // [?, ?, ?]
+	}, Mines: 1}
+
+	deductions, technique := Deduce(state)
+	if technique != Enumeration || !hasDeduction(deductions, game.Coordinate{X: 2, Y: 0}, false) {
+		log.Printf("Expected the mine count to prove 0_2 safe. Actual: %v %+v", technique, deductions)
+		test.Fail()
+	}
+}
+
+func TestAnalyzeProbability(test *testing.T) {
+	state := State{Field: [][]int{
+		{1, -1},  // [1, ?]
+		{-1, -1}, // [?, ?]
+	}, Mines: 1}
+
+	analysis := Analyze(state)
+	for _, coord := range state.HiddenTiles() {
+		if probability := analysis.Probability[coord]; probability < 0.33 || probability > 0.34 {
+			log.Printf("Expected every hidden tile to have a 1/3 chance of a mine. Actual: %v at %+v", probability, coord)
+			test.Fail()
+		}
+	}
+}
+
+func TestReview(test *testing.T) {
+	// The 1-2 pattern of TestDeduceSubset.
+	state := State{Field: [][]int{
+		{0, 0, 0, 0},     // [0, 0, 0, 0]
+		{1, 1, 2, 1},     // [1, 1, 2, 1]
+		{-1, -1, -1, -1}, // [?, ?, ?, ?]
+	}, Mines: 2}
+	mistake := Review(state, game.Coordinate{X: 3, Y: 2})
+	if mistake == nil || !mistake.Mine || mistake.Technique != Subset || len(mistake.Reasons) != 2 {
+		log.Printf("Expected revealing 2_3 to miss a subset deduction of a mine. Actual: %+v", mistake)
+		test.Fail()
+	}
+	if mistake := Review(state, game.Coordinate{X: 0, Y: 2}); mistake != nil {
+		log.Printf("Expected revealing the proven safe 2_0 to be sound. Actual: %+v", mistake)
+		test.Fail()
+	}
+
+	// The 1 cannot tell which of its neighbors is the mine, but the mine count proves the far column safe.
+	state = State{Field: [][]int{
+		{1, -1, -1},  // [1, ?, ?]
+		{-1, -1, -1}, // [?, ?, ?]
+	}, Mines: 1}
+	mistake = Review(state, game.Coordinate{X: 1, Y: 0})
+	if mistake == nil || mistake.Mine || mistake.Safe == nil || mistake.Safe.X != 2 || mistake.Technique != Enumeration {
+		log.Printf("Expected revealing 0_1 to be a guess while the far column was safe. Actual: %+v", mistake)
+		test.Fail()
+	}
+
+	// Nothing can be proven, so any reveal is a fair guess.
+	state = State{Field: [][]int{
+		{1, -1},  // [1, ?]
+		{-1, -1}, // [?, ?]
+	}, Mines: 1}
+	if mistake := Review(state, game.Coordinate{X: 1, Y: 1}); mistake != nil {
+		log.Printf("Expected a guess with nothing provable to be sound. Actual: %+v", mistake)
+		test.Fail()
+	}
+}
+
+func TestRateNoGuess(test *testing.T) {
+	board, _ := generation.FromLayout([]string{
+		"........",
+		"........",
+		"........",
+		"........",
+		"*.......",
+		"...*....",
+	})
+	rating := Rate(*board, game.Coordinate{X: 7, Y: 0})
+	if rating.Guesses != 0 || rating.Hardest < Single {
+		log.Printf("Expected the board to be solved by deduction. Actual: %+v", rating)
+		test.Fail()
+	}
+}
+
+func TestRateGuess(test *testing.T) {
+	// Every tile touches the only mine, so nothing can be proven until it is found by guessing.
+	board, _ := generation.FromLayout([]string{
+		"*.",
+		"..",
+	})
+	rating := Rate(*board, game.Coordinate{X: 1, Y: 1})
+	if rating.Guesses != 2 {
+		log.Printf("Expected 2 forced guesses. Actual: %+v", rating)
+		test.Fail()
+	}
+}
+
+func TestRateGeneratedBoards(test *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		board, err := generation.NewBoard(99, 30, 16, seed)
+		if err != nil {
+			log.Printf("Error generating board: %s", err)
+			test.FailNow()
+		}
+		rating := Rate(*board, DefaultStart(*board))
+		if rating.ThreeBV <= 0 || rating.Score <= 0 {
+			log.Printf("Expected a positive 3BV and score. Actual: %+v", rating)
+			test.Fail()
+		}
+	}
+}
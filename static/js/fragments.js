@@ -0,0 +1,50 @@
+// Progressive enhancement for the game page. Links carrying an hx-get attribute are fetched in the
+// background and the element named by the nearest hx-target is replaced with the returned fragment.
+// Without this script the same links navigate to a full page render.
+(function () {
+    function swap(url, source) {
+        var scope = source.closest("[hx-target]");
+        var target = scope && document.querySelector(scope.getAttribute("hx-target"));
+        if (!target) {
+            location.href = url;
+            return;
+        }
+        fetch(url, { headers: { "HX-Request": "true" } })
+            .then(function (response) {
+                if (!response.ok) {
+                    throw new Error(response.statusText);
+                }
+                return response.text();
+            })
+            .then(function (html) {
+                if (scope.getAttribute("hx-swap") === "outerHTML") {
+                    target.outerHTML = html;
+                } else {
+                    target.innerHTML = html;
+                }
+            })
+            .catch(function () {
+                location.href = url;
+            });
+    }
+
+    document.addEventListener("click", function (event) {
+        var link = event.target.closest("a[hx-get]");
+        if (!link || event.button !== 0 || event.ctrlKey || event.metaKey || event.shiftKey) {
+            return;
+        }
+        event.preventDefault();
+        swap(link.getAttribute("hx-get"), link);
+    });
+
+    // Runs in the capture phase so it replaces the inline fallback that navigates to the flag link.
+    document.addEventListener("contextmenu", function (event) {
+        var tile = event.target.closest("[data-flag]");
+        if (!tile) {
+            return;
+        }
+        event.preventDefault();
+        event.stopPropagation();
+        swap(tile.getAttribute("data-flag"), tile);
+    }, true);
+})();
@@ -0,0 +1,142 @@
+// Keeps the game page up to date while it is played from another tab or watched by a spectator.
+// The cells of each diff received over the game's WebSocket are redrawn in place. Pages that show more than
+// the board changing with each move, and diffs that end the game or change nothing on the board, fetch the
+// whole board fragment instead. In co-op games the cell under each player's pointer is shared and outlined
+// for everyone else.
+(function () {
+    var play = document.getElementById("play");
+    if (!play || !window.WebSocket) {
+        return;
+    }
+    var scheme = location.protocol === "https:" ? "wss://" : "ws://";
+    var socket = new WebSocket(scheme + location.host + play.getAttribute("data-live"));
+    var cursors = {};
+    // Exploded and Flagged are the values view.VisibleField gives a revealed mine and a flagged tile.
+    var Exploded = 999;
+    var Flagged = -2;
+
+    // Returns the contents of a cell of the square board for a visible value, as templates/squaretable.html
+    // draws them.
+    function cellHTML(game, location, value, readOnly) {
+        if (value === Exploded) {
+            return '<img src="/static/mine.png">';
+        }
+        if (value === 0) {
+            return '<div class="w-5 h-5"></div>';
+        }
+        if (value > 0) {
+            if (readOnly) {
+                return String(value);
+            }
+            var chord = game + "/chord/" + location;
+            return '<a class="w-5 h-5" href="' + chord + '" hx-get="' + chord + '">' + value + "</a>";
+        }
+        if (readOnly) {
+            return '<div class="w-5 h-5 bg-slate-200">' + (value === Flagged ? "&#9873;" : "") + "</div>";
+        }
+        if (value === Flagged) {
+            var unflag = game + "/flag/" + location + "?flagged=false";
+            return '<a class="w-5 h-5" id="' + location + '" href="' + unflag + '" hx-get="' + unflag + '">' +
+                '<div class="w-5 h-5 bg-slate-200">&#9873;</div></a>';
+        }
+        var click = game + "/click/" + location;
+        var flag = game + "/flag/" + location + "?flagged=true";
+        return '<a class="w-5 h-5" id="' + location + '" href="' + click + '" hx-get="' + click + '"' +
+            ' data-flag="' + flag + '" oncontextmenu="location.href=\'' + flag + '\'; return false;">' +
+            '<div class="w-5 h-5 bg-slate-200"></div></a>';
+    }
+
+    // Redraws the changed cells of a diff, returning false if the page has to be fetched again instead.
+    function patch(update) {
+        var current = document.getElementById("play");
+        if (!current || current.getAttribute("data-patch") !== "true" || update.won || update.lost ||
+            !update.cells || update.cells.length === 0) {
+            return false;
+        }
+        var cells = [];
+        for (var index = 0; index < update.cells.length; index++) {
+            var change = update.cells[index];
+            var cell = document.querySelector('[data-cell="' + change.y + "_" + change.x + '"]');
+            if (!cell) {
+                return false;
+            }
+            cells.push(cell);
+        }
+        var game = current.getAttribute("data-fragment");
+        var readOnly = current.getAttribute("data-readonly") === "true";
+        update.cells.forEach(function (change, index) {
+            cells[index].innerHTML = cellHTML(game, change.y + "_" + change.x, change.value, readOnly);
+        });
+        var remaining = document.getElementById("remaining");
+        if (remaining) {
+            remaining.textContent = update.remaining;
+        }
+        return true;
+    }
+
+    function showCursors() {
+        document.querySelectorAll("[data-cell].outline").forEach(function (cell) {
+            cell.classList.remove("outline", "outline-2", "outline-blue-500");
+            cell.removeAttribute("title");
+        });
+        Object.keys(cursors).forEach(function (player) {
+            var pointer = cursors[player];
+            var cell = document.querySelector('[data-cell="' + pointer.y + "_" + pointer.x + '"]');
+            if (cell) {
+                cell.classList.add("outline", "outline-2", "outline-blue-500");
+                cell.setAttribute("title", player);
+            }
+        });
+    }
+
+    socket.onmessage = function (event) {
+        var update = JSON.parse(event.data);
+        var spectators = document.getElementById("spectators");
+        if (spectators) {
+            spectators.textContent = update.spectators + " watching";
+        }
+        if (update.type === "cursor") {
+            cursors[update.pointer.player] = update.pointer;
+            showCursors();
+            return;
+        }
+        if (update.type !== "diff") {
+            return;
+        }
+        if (patch(update)) {
+            showCursors();
+            return;
+        }
+        fetch(play.getAttribute("data-fragment"), { headers: { "HX-Request": "true" } })
+            .then(function (response) {
+                return response.ok ? response.text() : Promise.reject(response.statusText);
+            })
+            .then(function (html) {
+                document.getElementById("play").outerHTML = html;
+                showCursors();
+            })
+            .catch(function () {});
+    };
+
+    if (play.getAttribute("data-coop") !== "true") {
+        return;
+    }
+    // Pointer moves are sent at most every 100ms, and only when they reach another cell.
+    var last = "";
+    var sent = 0;
+    document.addEventListener("mouseover", function (event) {
+        var cell = event.target.closest && event.target.closest("[data-cell]");
+        if (!cell || socket.readyState !== WebSocket.OPEN) {
+            return;
+        }
+        var key = cell.getAttribute("data-cell");
+        var now = Date.now();
+        if (key === last || now - sent < 100) {
+            return;
+        }
+        last = key;
+        sent = now;
+        var parts = key.split("_");
+        socket.send(JSON.stringify({ type: "cursor", y: Number(parts[0]), x: Number(parts[1]) }));
+    });
+})();
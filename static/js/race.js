@@ -0,0 +1,45 @@
+// Keeps a race's standings up to date. Every state pushed over the race's WebSocket triggers a fetch of the
+// standings fragment, and the countdown is ticked locally until the race starts.
+(function () {
+    var panel = document.getElementById("race-status");
+    if (!panel || !window.WebSocket) {
+        return;
+    }
+    var id = panel.getAttribute("data-race");
+    var timer = null;
+
+    function refresh() {
+        fetch("/race/" + id, { headers: { "HX-Request": "true" } })
+            .then(function (response) {
+                return response.ok ? response.text() : Promise.reject(response.statusText);
+            })
+            .then(function (html) {
+                document.getElementById("race-status").outerHTML = html;
+                countdown();
+            })
+            .catch(function () {});
+    }
+
+    function countdown() {
+        clearInterval(timer);
+        var label = document.querySelector("#race-status [data-start]");
+        if (!label) {
+            return;
+        }
+        var start = Number(label.getAttribute("data-start"));
+        timer = setInterval(function () {
+            var remaining = Math.ceil((start - Date.now()) / 1000);
+            if (remaining <= 0) {
+                clearInterval(timer);
+                refresh();
+                return;
+            }
+            label.textContent = "Starting in " + remaining + "s";
+        }, 250);
+    }
+
+    var scheme = location.protocol === "https:" ? "wss://" : "ws://";
+    var socket = new WebSocket(scheme + location.host + "/race/" + id + "/live");
+    socket.onmessage = refresh;
+    countdown();
+})();
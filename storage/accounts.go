@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionLength is how long a session lasts after signing in.
+const SessionLength = 30 * 24 * time.Hour
+
+// Usernames are at most 32 characters and guest names are always 64, so the two can never collide.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+// Guest names are the hex encoding of a SHA-256 hash.
+var guestPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+var ErrUsernameTaken = errors.New("username is already taken")
+var ErrInvalidCredentials = errors.New("username or password is incorrect")
+var ErrNoSession = errors.New("session does not exist or has expired")
+
+// Account is a registered player.
+type Account struct {
+	Username string
+	// Created is a Unix time in milliseconds.
+	Created int64
+}
+
+// Returns true if a name has the form given to guests, which no username can have.
+func ValidGuest(name string) bool {
+	return guestPattern.MatchString(name)
+}
+
+// Returns an error describing why a username and password cannot be registered, or nil if they can.
+func ValidateCredentials(username string, password string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("usernames must be 3 to 32 letters, numbers, dashes or underscores")
+	}
+	if len(password) < 8 {
+		return fmt.Errorf("passwords must be at least 8 characters")
+	}
+	if len(password) > 72 {
+		return fmt.Errorf("passwords must be at most 72 characters")
+	}
+	return nil
+}
+
+// Registers a new account with a bcrypt hash of its password.
+func (db *DB) CreateAccount(username string, password string) (*Account, error) {
+	if err := ValidateCredentials(username, password); err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	account := Account{username, time.Now().UnixMilli()}
+	_, err = db.sql.Exec(`INSERT INTO accounts (username, password_hash, created) VALUES (?, ?, ?)`,
+		account.Username, hash, account.Created)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		return nil, ErrUsernameTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Returns the account matching a username and password, or ErrInvalidCredentials.
+func (db *DB) Authenticate(username string, password string) (*Account, error) {
+	account := Account{}
+	var hash []byte
+	err := db.sql.QueryRow(`SELECT username, password_hash, created FROM accounts WHERE username = ?`, username).
+		Scan(&account.Username, &hash, &account.Created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &account, nil
+}
+
+// Starts a session for an account and returns its token.
+func (db *DB) CreateSession(username string) (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buffer)
+	expires := time.Now().Add(SessionLength).UnixMilli()
+	_, err := db.sql.Exec(`INSERT INTO sessions (token, username, expires) VALUES (?, ?, ?)`, token, username, expires)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Returns the username signed in with a session token, or ErrNoSession.
+func (db *DB) SessionUser(token string) (string, error) {
+	var username string
+	err := db.sql.QueryRow(`SELECT username FROM sessions WHERE token = ? AND expires > ?`, token, time.Now().UnixMilli()).
+		Scan(&username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNoSession
+	}
+	return username, err
+}
+
+// Ends a session along with any expired sessions.
+func (db *DB) DeleteSession(token string) error {
+	_, err := db.sql.Exec(`DELETE FROM sessions WHERE token = ? OR expires <= ?`, token, time.Now().UnixMilli())
+	return err
+}
+
+// Moves the games, results and runs a guest has in the database to an account. Race results the account
+// already has for the same race are kept. The saves of the games must be reassigned separately.
+func (db *DB) ClaimGuest(guest string, username string) error {
+	transaction, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer transaction.Rollback()
+	for _, statement := range []string{
+		`UPDATE games SET player = ? WHERE player = ?`,
+		`UPDATE leaderboard SET player = ? WHERE player = ?`,
+		`UPDATE runs SET player = ? WHERE player = ?`,
+		`UPDATE OR IGNORE race_results SET player = ? WHERE player = ?`,
+	} {
+		if _, err := transaction.Exec(statement, username, guest); err != nil {
+			return err
+		}
+	}
+	return transaction.Commit()
+}
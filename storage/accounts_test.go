@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+)
+
+func openTestDB(test *testing.T) *DB {
+	db, err := Open(filepath.Join(test.TempDir(), "test.db"))
+	if err != nil {
+		log.Printf("Error opening database: %s", err)
+		test.FailNow()
+	}
+	test.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestAccounts(test *testing.T) {
+	db := openTestDB(test)
+
+	if _, err := db.CreateAccount("sweeper", "correct horse"); err != nil {
+		log.Printf("Error creating account: %s", err)
+		test.FailNow()
+	}
+	if _, err := db.CreateAccount("sweeper", "another password"); !errors.Is(err, ErrUsernameTaken) {
+		log.Printf("Expected ErrUsernameTaken. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := db.Authenticate("sweeper", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		log.Printf("Expected ErrInvalidCredentials for a wrong password. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := db.Authenticate("nobody", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		log.Printf("Expected ErrInvalidCredentials for an unknown user. Actual: %v", err)
+		test.Fail()
+	}
+	account, err := db.Authenticate("sweeper", "correct horse")
+	if err != nil || account.Username != "sweeper" {
+		log.Printf("Expected to authenticate. Actual: %+v Error: %v", account, err)
+		test.Fail()
+	}
+}
+
+func TestAccountValidation(test *testing.T) {
+	db := openTestDB(test)
+	invalid := [][2]string{
+		{"ab", "long enough"},
+		{"has space", "long enough"},
+		{"0123456789abcdef0123456789abcdef0", "long enough"},
+		{"valid", "short"},
+	}
+	for _, credentials := range invalid {
+		if _, err := db.CreateAccount(credentials[0], credentials[1]); err == nil {
+			log.Printf("Expected credentials to be rejected: %q", credentials)
+			test.Fail()
+		}
+	}
+}
+
+func TestSessions(test *testing.T) {
+	db := openTestDB(test)
+	db.CreateAccount("sweeper", "correct horse")
+
+	token, err := db.CreateSession("sweeper")
+	if err != nil {
+		log.Printf("Error creating session: %s", err)
+		test.FailNow()
+	}
+	if username, err := db.SessionUser(token); err != nil || username != "sweeper" {
+		log.Printf("Expected session to belong to sweeper. Actual: %s Error: %v", username, err)
+		test.Fail()
+	}
+	db.DeleteSession(token)
+	if _, err := db.SessionUser(token); !errors.Is(err, ErrNoSession) {
+		log.Printf("Expected ErrNoSession after signing out. Actual: %v", err)
+		test.Fail()
+	}
+}
+
+func TestUsernameIsNotAGuest(test *testing.T) {
+	db := openTestDB(test)
+	if _, err := db.CreateAccount("sweeper", "correct horse"); err != nil {
+		log.Printf("Error creating account: %s", err)
+		test.FailNow()
+	}
+	if ValidGuest("sweeper") {
+		log.Printf("Expected a registered username to be rejected as a guest name.")
+		test.Fail()
+	}
+	for _, name := range []string{
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	} {
+		if !ValidGuest(name) {
+			log.Printf("Expected a generated guest name to be accepted: %q", name)
+			test.Fail()
+		}
+	}
+	for _, name := range []string{
+		"0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcde",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdeg",
+	} {
+		if ValidGuest(name) {
+			log.Printf("Expected a malformed guest name to be rejected: %q", name)
+			test.Fail()
+		}
+	}
+}
+
+func TestClaimGuest(test *testing.T) {
+	db := openTestDB(test)
+	guest := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	if err := db.IndexGame("unfinished", &GameSave{Player: guest, Difficulty: "beginner", Started: 1000}); err != nil {
+		log.Printf("Error indexing game: %s", err)
+		test.FailNow()
+	}
+	_, err := db.sql.Exec(`INSERT INTO leaderboard (name, player, difficulty, duration_ms, three_bv_ps, finished)
+		VALUES ('won', ?, 'beginner', 10000, 2, 20000)`, guest)
+	if err != nil {
+		log.Printf("Error adding result: %s", err)
+		test.FailNow()
+	}
+
+	if err := db.ClaimGuest(guest, "sweeper"); err != nil {
+		log.Printf("Error claiming guest: %s", err)
+		test.FailNow()
+	}
+	if names, err := db.GameNames("sweeper"); err != nil || len(names) != 1 || names[0] != "unfinished" {
+		log.Printf("Expected the guest's game to move to the account. Actual: %v Error: %v", names, err)
+		test.Fail()
+	}
+	if names, _ := db.GameNames(guest); len(names) != 0 {
+		log.Printf("Expected the guest to have no games left. Actual: %v", names)
+		test.Fail()
+	}
+	if top, err := db.TopResults("beginner", ByTime, 10); err != nil || len(top) != 1 || top[0].Player != "sweeper" {
+		log.Printf("Expected the guest's result to move to the account. Actual: %+v Error: %v", top, err)
+		test.Fail()
+	}
+}
+
+func TestReassign(test *testing.T) {
+	gameSave := &GameSave{
+		Player:        "guest",
+		Coop:          true,
+		Contributors:  []string{"guest", "friend"},
+		Moves:         []Move{{Player: "guest"}, {Player: "friend"}},
+		Contributions: []game.Contribution{{Player: "friend"}, {Player: "guest"}},
+	}
+	gameSave.Reassign("guest", "sweeper")
+	if gameSave.Player != "sweeper" || gameSave.Contributors[0] != "sweeper" || gameSave.Contributors[1] != "friend" {
+		log.Printf("Expected the owner and contributor to be reassigned. Actual: %+v", gameSave)
+		test.Fail()
+	}
+	if gameSave.Moves[0].Player != "sweeper" || gameSave.Moves[1].Player != "friend" || gameSave.Contributions[1].Player != "sweeper" {
+		log.Printf("Expected the guest's moves and contribution to be reassigned. Actual: %+v", gameSave)
+		test.Fail()
+	}
+}
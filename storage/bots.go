@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// BotPrefix starts the player name of every bot. Usernames and guest names cannot contain its colon.
+const BotPrefix = "bot:"
+
+var ErrBotNameTaken = errors.New("bot name is already taken")
+var ErrNoBot = errors.New("bot token is not valid")
+
+// Bot is a programmatic player registered by an account.
+type Bot struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	// Created is a Unix time in milliseconds.
+	Created int64 `json:"created"`
+}
+
+// Returns the player name games played by a bot are saved under.
+func (bot Bot) Player() string {
+	return BotPrefix + bot.Name
+}
+
+// Returns the leaderboard bot results of a difficulty are ranked on, kept apart from players' results.
+func BotLeaderboard(difficulty string) string {
+	return BotPrefix + difficulty
+}
+
+// Registers a bot for an account and returns the token it authenticates with. Only a hash of the token is
+// stored, so it cannot be shown again.
+func (db *DB) CreateBot(owner string, name string) (string, error) {
+	if !usernamePattern.MatchString(name) {
+		return "", fmt.Errorf("bot names must be 3 to 32 letters, numbers, dashes or underscores")
+	}
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buffer)
+	_, err := db.sql.Exec(`INSERT INTO bots (name, owner, token_hash, created) VALUES (?, ?, ?, ?)`,
+		name, owner, hashToken(token), time.Now().UnixMilli())
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		return "", ErrBotNameTaken
+	}
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Returns the bot a token belongs to, or ErrNoBot.
+func (db *DB) BotForToken(token string) (Bot, error) {
+	bot := Bot{}
+	err := db.sql.QueryRow(`SELECT name, owner, created FROM bots WHERE token_hash = ?`, hashToken(token)).
+		Scan(&bot.Name, &bot.Owner, &bot.Created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Bot{}, ErrNoBot
+	}
+	return bot, err
+}
+
+// Returns the bots registered by an account, oldest first.
+func (db *DB) Bots(owner string) ([]Bot, error) {
+	rows, err := db.sql.Query(`SELECT name, owner, created FROM bots WHERE owner = ? ORDER BY created, name`, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	bots := []Bot{}
+	for rows.Next() {
+		bot := Bot{}
+		if err := rows.Scan(&bot.Name, &bot.Owner, &bot.Created); err != nil {
+			return nil, err
+		}
+		bots = append(bots, bot)
+	}
+	return bots, rows.Err()
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+	"log"
+	"testing"
+)
+
+func TestBots(test *testing.T) {
+	db := openTestDB(test)
+	if _, err := db.CreateAccount("sweeper", "correct horse"); err != nil {
+		log.Printf("Error creating account: %s", err)
+		test.FailNow()
+	}
+	token, err := db.CreateBot("sweeper", "flagbot")
+	if err != nil {
+		log.Printf("Error creating bot: %s", err)
+		test.FailNow()
+	}
+	if _, err := db.CreateBot("sweeper", "flagbot"); !errors.Is(err, ErrBotNameTaken) {
+		log.Printf("Expected ErrBotNameTaken. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := db.CreateBot("sweeper", "bad name"); err == nil {
+		log.Printf("Expected an invalid bot name to be rejected.")
+		test.Fail()
+	}
+	bot, err := db.BotForToken(token)
+	if err != nil || bot.Name != "flagbot" || bot.Owner != "sweeper" || bot.Player() != "bot:flagbot" {
+		log.Printf("Expected the token to find the bot. Actual: %+v %v", bot, err)
+		test.Fail()
+	}
+	if _, err := db.BotForToken("not a token"); !errors.Is(err, ErrNoBot) {
+		log.Printf("Expected ErrNoBot for an unknown token. Actual: %v", err)
+		test.Fail()
+	}
+	if bots, err := db.Bots("sweeper"); err != nil || len(bots) != 1 {
+		log.Printf("Expected one bot for the account. Actual: %+v %v", bots, err)
+		test.Fail()
+	}
+}
+
+func TestBotLeaderboardIsSeparate(test *testing.T) {
+	db := openTestDB(test)
+	botSave := wonTestSave(test, "bot:flagbot", 1, 10)
+	botSave.Bot = "flagbot"
+	if err := db.SubmitResult("bot", botSave); err != nil {
+		log.Printf("Error submitting bot result: %s", err)
+		test.FailNow()
+	}
+	if err := db.SubmitResult("person", wonTestSave(test, "alice", 1, 100)); err != nil {
+		log.Printf("Error submitting result: %s", err)
+		test.FailNow()
+	}
+	people, _ := db.TopResults("beginner", ByTime, 10)
+	bots, _ := db.TopResults(BotLeaderboard("beginner"), ByTime, 10)
+	if len(people) != 1 || people[0].Player != "alice" || len(bots) != 1 || bots[0].Player != "bot:flagbot" {
+		log.Printf("Expected bots and people on separate leaderboards. Actual: %+v %+v", people, bots)
+		test.Fail()
+	}
+
+	impostor := wonTestSave(test, "alice", 1, 10)
+	impostor.Bot = "flagbot"
+	if ValidateResult(impostor) == nil {
+		log.Printf("Expected a bot game saved under another player to be rejected.")
+		test.Fail()
+	}
+}
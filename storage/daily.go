@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var DailyCrumb = filepath.Join(PathCrumb, "daily")
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// Attempt records a player's single attempt at a daily challenge.
+type Attempt struct {
+	Player string `json:"player"`
+	Game   string `json:"game"`
+	// Started and Finished are Unix times in milliseconds.
+	Started  int64 `json:"started"`
+	Finished int64 `json:"finished,omitempty"`
+	Won      bool  `json:"won"`
+}
+
+// Returns the time taken to finish the attempt, or zero if it has not finished.
+func (attempt *Attempt) Duration() time.Duration {
+	if attempt.Finished == 0 {
+		return 0
+	}
+	return time.Duration(attempt.Finished-attempt.Started) * time.Millisecond
+}
+
+func attemptPath(date string, player string) (string, error) {
+	if !datePattern.MatchString(date) {
+		return "", fmt.Errorf("invalid challenge date: %q", date)
+	}
+	if !ValidPlayer(player) {
+		return "", fmt.Errorf("invalid player name: %q", player)
+	}
+	return filepath.Join(DailyCrumb, date, player+".json"), nil
+}
+
+// Loads a player's attempt at the challenge of a date. The error wraps fs.ErrNotExist if they have not attempted it.
+func LoadAttempt(date string, player string) (*Attempt, error) {
+	path, err := attemptPath(date, player)
+	if err != nil {
+		return &Attempt{}, err
+	}
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return &Attempt{}, err
+	}
+	attempt := Attempt{}
+	if err := json.Unmarshal(buffer, &attempt); err != nil {
+		return &Attempt{}, err
+	}
+	return &attempt, nil
+}
+
+// Saves an attempt at the challenge of a date.
+func (attempt *Attempt) Save(date string) error {
+	path, err := attemptPath(date, attempt.Player)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(attempt)
+}
+
+// Moves a guest's attempts at daily challenges to an account, except on days the account has its own attempt.
+func ClaimAttempts(guest string, username string) error {
+	entries, err := os.ReadDir(DailyCrumb)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		date := entry.Name()
+		attempt, err := LoadAttempt(date, guest)
+		if err != nil {
+			continue
+		}
+		if _, err := LoadAttempt(date, username); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		attempt.Player = username
+		if err := attempt.Save(date); err != nil {
+			return err
+		}
+		path, _ := attemptPath(date, guest)
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns every attempt at the challenge of a date.
+func Attempts(date string) ([]Attempt, error) {
+	if !datePattern.MatchString(date) {
+		return nil, fmt.Errorf("invalid challenge date: %q", date)
+	}
+	entries, err := os.ReadDir(filepath.Join(DailyCrumb, date))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Attempt{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	attempts := []Attempt{}
+	for _, entry := range entries {
+		player, found := strings.CutSuffix(entry.Name(), ".json")
+		if !found {
+			continue
+		}
+		attempt, err := LoadAttempt(date, player)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, *attempt)
+	}
+	return attempts, nil
+}
+
+// Returns the won attempts at the challenge of a date, fastest first.
+func DailyLeaderboard(date string) ([]Attempt, error) {
+	attempts, err := Attempts(date)
+	if err != nil {
+		return nil, err
+	}
+	won := []Attempt{}
+	for _, attempt := range attempts {
+		if attempt.Won {
+			won = append(won, attempt)
+		}
+	}
+	sort.SliceStable(won, func(i, j int) bool {
+		if won[i].Duration() != won[j].Duration() {
+			return won[i].Duration() < won[j].Duration()
+		}
+		return won[i].Finished < won[j].Finished
+	})
+	return won, nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"testing"
+)
+
+func TestDailyLeaderboard(test *testing.T) {
+	DailyCrumb = test.TempDir()
+	date := "2026-10-19"
+
+	if _, err := LoadAttempt(date, "nobody"); !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Expected a missing attempt to produce fs.ErrNotExist. Actual: %v", err)
+		test.Fail()
+	}
+
+	attempts := []Attempt{
+		{Player: "slow", Game: "a", Started: 1000, Finished: 61000, Won: true},
+		{Player: "fast", Game: "b", Started: 2000, Finished: 32000, Won: true},
+		{Player: "lost", Game: "c", Started: 3000, Finished: 4000, Won: false},
+		{Player: "playing", Game: "d", Started: 5000},
+	}
+	for _, attempt := range attempts {
+		if err := attempt.Save(date); err != nil {
+			log.Printf("Error saving attempt: %s", err)
+			test.FailNow()
+		}
+	}
+
+	leaderboard, err := DailyLeaderboard(date)
+	if err != nil {
+		log.Printf("Error loading leaderboard: %s", err)
+		test.FailNow()
+	}
+	if len(leaderboard) != 2 || leaderboard[0].Player != "fast" || leaderboard[1].Player != "slow" {
+		log.Printf("Leaderboard did not rank won attempts by time. Actual: %+v", leaderboard)
+		test.Fail()
+	}
+}
+
+func TestAttemptInvalidDate(test *testing.T) {
+	attempt := Attempt{Player: "player"}
+	if err := attempt.Save("../../etc"); err == nil {
+		log.Printf("Expected Attempt#Save to reject an invalid date.")
+		test.Fail()
+	}
+}
+
+func TestClaimAttempts(test *testing.T) {
+	DailyCrumb = test.TempDir()
+	for _, attempt := range []struct {
+		date string
+		Attempt
+	}{
+		{"2026-10-18", Attempt{Player: "guest", Game: "guest-only"}},
+		{"2026-10-19", Attempt{Player: "guest", Game: "both-guest"}},
+		{"2026-10-19", Attempt{Player: "sweeper", Game: "both-account"}},
+	} {
+		if err := attempt.Save(attempt.date); err != nil {
+			log.Printf("Error saving attempt: %s", err)
+			test.FailNow()
+		}
+	}
+
+	if err := ClaimAttempts("guest", "sweeper"); err != nil {
+		log.Printf("Error claiming attempts: %s", err)
+		test.FailNow()
+	}
+	if attempt, err := LoadAttempt("2026-10-18", "sweeper"); err != nil || attempt.Game != "guest-only" {
+		log.Printf("Expected the guest's attempt to move to the account. Actual: %+v Error: %v", attempt, err)
+		test.Fail()
+	}
+	if _, err := LoadAttempt("2026-10-18", "guest"); !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Expected the guest's moved attempt to be removed. Actual: %v", err)
+		test.Fail()
+	}
+	if attempt, err := LoadAttempt("2026-10-19", "sweeper"); err != nil || attempt.Game != "both-account" {
+		log.Printf("Expected the account's own attempt to be kept. Actual: %+v Error: %v", attempt, err)
+		test.Fail()
+	}
+}
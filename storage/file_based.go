@@ -9,9 +9,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/deadly990/gominesweeper/game"
 	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/solver"
 )
 
 var cwd, _ = filepath.Abs(".")
@@ -20,6 +23,12 @@ var PathCrumb = filepath.Join(cwd, "saves")
 type Move struct {
 	X int `json:"x"`
 	Y int `json:"y"`
+	// Action is empty for reveals.
+	Action game.Action `json:"action,omitempty"`
+	// T is the time since the game started in milliseconds.
+	T int64 `json:"t,omitempty"`
+	// Player made the move in a cooperative game.
+	Player string `json:"player,omitempty"`
 }
 
 // GameSave stores all the data required to represent and rebuild a Game.
@@ -29,6 +38,49 @@ type GameSave struct {
 	Height    int    `json:"height"`
 	MineCount int    `json:"mineCount"`
 	Moves     []Move `json:"moves"`
+	// Layout is set for boards that were not generated from a seed, such as campaign levels.
+	Layout []string `json:"layout,omitempty"`
+	// Topology is empty for flat boards.
+	Topology generation.Topology `json:"topology,omitempty"`
+	// Neighborhood is the tiles numbers count, empty for the standard eight.
+	Neighborhood generation.Neighborhood `json:"neighborhood,omitempty"`
+	// MinesPerTile is the most mines a tile of a generated board can hold, empty for one.
+	MinesPerTile int `json:"minesPerTile,omitempty"`
+	// Layers is the number of layers of a three dimensional board, whose Height counts the rows of every layer.
+	// It is empty for flat boards.
+	Layers int `json:"layers,omitempty"`
+	// Lives is the number of mines the player may hit before losing, empty for classic games, and LivesUsed the
+	// number they hit.
+	Lives     int    `json:"lives,omitempty"`
+	LivesUsed int    `json:"livesUsed,omitempty"`
+	Level     string `json:"level,omitempty"`
+	Daily     string `json:"daily,omitempty"`
+	Race      string `json:"race,omitempty"`
+	// Run is the time attack or endless run the game is a stage of, empty otherwise.
+	Run string `json:"run,omitempty"`
+	// Training games review every reveal, recording Mistakes the player can rewind to before, and are never
+	// ranked. Retries counts the rewinds.
+	Training bool             `json:"training,omitempty"`
+	Mistakes []solver.Mistake `json:"mistakes,omitempty"`
+	Retries  int              `json:"retries,omitempty"`
+	Player   string           `json:"player,omitempty"`
+	// Bot is the name of the bot playing the game through the bot API, empty for games played by people.
+	Bot string `json:"bot,omitempty"`
+	// NoSpectators is set when the owner has disabled watching the game through its share link.
+	NoSpectators bool `json:"noSpectators,omitempty"`
+	// Coop games are played by every one of their Contributors, starting with the owner.
+	Coop          bool                `json:"coop,omitempty"`
+	Contributors  []string            `json:"contributors,omitempty"`
+	Contributions []game.Contribution `json:"contributions,omitempty"`
+	// Difficulty is the preset the board was generated with, or the mode it was played in.
+	Difficulty string `json:"difficulty,omitempty"`
+	// Started and Finished are Unix times in milliseconds.
+	Started  int64 `json:"started,omitempty"`
+	Finished int64 `json:"finished,omitempty"`
+	Won      bool  `json:"won,omitempty"`
+	// Rating is the difficulty of the board from the tile the game started on.
+	Rating *solver.Rating `json:"rating,omitempty"`
+	Stats  *game.Stats    `json:"stats,omitempty"`
 }
 
 // Returns a reference to a GameSave from a Game.
@@ -36,45 +88,146 @@ func FromGame(game game.Game) *GameSave {
 	seed := game.Board.Seed
 	width, height := game.Board.BoardSize()
 	mineCount := game.Board.Mines
-	savedMoves := translateCoordinates(game.Moves)
-	return &GameSave{seed, width, height, mineCount, savedMoves}
+	savedMoves := translateGameMoves(game.Moves)
+	return &GameSave{Seed: seed, Width: width, Height: height, MineCount: mineCount, Moves: savedMoves,
+		Topology: game.Board.Topology, Neighborhood: game.Board.Neighborhood, MinesPerTile: game.Board.MinesPerTile,
+		Layers: game.Board.Layers, Lives: game.Lives, LivesUsed: len(game.Hits)}
+}
+
+// Returns true if a player owns a game. Games saved without an owner belong to everyone.
+func (gameSave *GameSave) OwnedBy(player string) bool {
+	return gameSave.Player == "" || gameSave.Player == player
+}
+
+// Returns true if a player may play a game: its owner, or anyone who has joined a cooperative game.
+func (gameSave *GameSave) PlayableBy(player string) bool {
+	return gameSave.OwnedBy(player) || (gameSave.Coop && slices.Contains(gameSave.Contributors, player))
+}
+
+// Gives everything a player did in a game to another player, such as a guest who has signed in.
+func (gameSave *GameSave) Reassign(from string, to string) {
+	if gameSave.Player == from {
+		gameSave.Player = to
+	}
+	for index := range gameSave.Contributors {
+		if gameSave.Contributors[index] == from {
+			gameSave.Contributors[index] = to
+		}
+	}
+	for index := range gameSave.Moves {
+		if gameSave.Moves[index].Player == from {
+			gameSave.Moves[index].Player = to
+		}
+	}
+	for index := range gameSave.Contributions {
+		if gameSave.Contributions[index].Player == from {
+			gameSave.Contributions[index].Player = to
+		}
+	}
+}
+
+// Returns the time taken to finish the game, or zero if it has not finished.
+func (gameSave *GameSave) Duration() time.Duration {
+	if gameSave.Finished == 0 {
+		return 0
+	}
+	return time.Duration(gameSave.Finished-gameSave.Started) * time.Millisecond
+}
+
+// Returns the time since the game started, or zero if its start was not recorded.
+func (gameSave *GameSave) Elapsed(now time.Time) time.Duration {
+	if gameSave.Started == 0 {
+		return 0
+	}
+	return now.Sub(time.UnixMilli(gameSave.Started))
+}
+
+// Records the moves and statistics of a Game into an existing GameSave, keeping the rest of its fields.
+func (gameSave *GameSave) Record(game game.Game) {
+	gameSave.Moves = translateGameMoves(game.Moves)
+	gameSave.LivesUsed = len(game.Hits)
+	stats := game.Stats()
+	gameSave.Stats = &stats
+	if gameSave.Coop {
+		gameSave.Contributions = game.Contributions()
+	}
 }
 
 // Recreates and returns a Game from a GameSave.
 func (gameSave *GameSave) ToGame() *game.Game {
-	board, err := generation.NewBoard(
-		gameSave.MineCount,
-		gameSave.Width,
-		gameSave.Height,
-		gameSave.Seed,
-	)
+	return gameSave.ReplayTo(len(gameSave.Moves))
+}
+
+// Recreates and returns a Game as it was after the first step moves of a GameSave.
+func (gameSave *GameSave) ReplayTo(step int) *game.Game {
+	board, err := gameSave.board()
 	if err != nil {
 		log.Fatalf("Encountered an error in converting GameSave to Game: %s", err)
 	}
 	game := game.NewGame(*board)
-	game.Moves = translateMoves(gameSave.Moves)
-	for _, move := range game.Moves { // Make all the moves stored in the save without adding to Moves.
-		game.Clear(move)
+	game.Lives = gameSave.Lives
+	step = max(0, min(step, len(gameSave.Moves)))
+	for _, move := range translateMoves(gameSave.Moves[:step]) {
+		game.Play(move)
 	}
 	return game
 }
 
-func translateCoordinates(coordinates []game.Coordinate) []Move {
+func (gameSave *GameSave) board() (*generation.Board, error) {
+	var board *generation.Board
+	var err error
+	if gameSave.Layout != nil {
+		board, err = generation.FromLayout(gameSave.Layout)
+	} else {
+		board, err = generation.NewMultiMineBoard(
+			gameSave.MineCount,
+			max(1, gameSave.MinesPerTile),
+			gameSave.Width,
+			gameSave.Height,
+			gameSave.Seed,
+		)
+	}
+	if err != nil {
+		return board, err
+	}
+	if gameSave.Topology != generation.Plane {
+		board = board.OnTopology(gameSave.Topology)
+	}
+	if gameSave.Neighborhood != nil {
+		if board, err = board.WithNeighborhood(gameSave.Neighborhood); err != nil {
+			return board, err
+		}
+	}
+	if gameSave.Layers > 1 {
+		return board.InLayers(gameSave.Layers)
+	}
+	return board, nil
+}
+
+func translateGameMoves(gameMoves []game.Move) []Move {
 	moves := []Move{}
-	for _, coordinate := range coordinates {
-		translation := Move{coordinate.X, coordinate.Y}
+	for _, gameMove := range gameMoves {
+		translation := Move{X: gameMove.X, Y: gameMove.Y, T: gameMove.Elapsed.Milliseconds(), Player: gameMove.Player}
+		if gameMove.Action != game.Reveal {
+			translation.Action = gameMove.Action
+		}
 		moves = append(moves, translation)
 	}
 	return moves
 }
 
-func translateMoves(moves []Move) []game.Coordinate {
-	coordinates := []game.Coordinate{}
+func translateMoves(moves []Move) []game.Move {
+	gameMoves := []game.Move{}
 	for _, move := range moves {
-		translation := game.Coordinate{X: move.X, Y: move.Y}
-		coordinates = append(coordinates, translation)
+		translation := game.Move{
+			Coordinate: game.Coordinate{X: move.X, Y: move.Y},
+			Action:     move.Action,
+			Elapsed:    time.Duration(move.T) * time.Millisecond,
+			Player:     move.Player,
+		}
+		gameMoves = append(gameMoves, translation)
 	}
-	return coordinates
+	return gameMoves
 }
 
 func (gameSave *GameSave) Save(name string) error {
@@ -119,9 +272,10 @@ func (gameSave *GameSave) Decode(reader io.Reader) error {
 	return decoder.Decode(&gameSave)
 }
 
-// Returns true if a Move's X and Y are equivalent to the passed in Move, otherwise false.
+// Returns true if a Move's fields are equivalent to the passed in Move, otherwise false.
 func (receiver *Move) EquivalentTo(other Move) bool {
-	return receiver.X == other.X && receiver.Y == other.Y
+	return receiver.X == other.X && receiver.Y == other.Y && receiver.Action == other.Action && receiver.T == other.T &&
+		receiver.Player == other.Player
 }
 
 // Returns true if a GameSave has equivalent fields to the passed in GameSave, otherwise false.
@@ -138,6 +292,33 @@ func (receiver *GameSave) EquivalentTo(other GameSave) bool {
 	if receiver.MineCount != other.MineCount {
 		return false
 	}
+	if receiver.Level != other.Level || receiver.Daily != other.Daily || receiver.Race != other.Race || receiver.Run != other.Run ||
+		receiver.Player != other.Player {
+		return false
+	}
+	if receiver.Difficulty != other.Difficulty || receiver.Won != other.Won || receiver.NoSpectators != other.NoSpectators ||
+		receiver.Bot != other.Bot || receiver.Topology != other.Topology ||
+		!slices.Equal(receiver.Neighborhood, other.Neighborhood) || receiver.MinesPerTile != other.MinesPerTile ||
+		receiver.Layers != other.Layers || receiver.Lives != other.Lives || receiver.LivesUsed != other.LivesUsed {
+		return false
+	}
+	if receiver.Training != other.Training || receiver.Retries != other.Retries || len(receiver.Mistakes) != len(other.Mistakes) {
+		return false
+	}
+	if receiver.Coop != other.Coop || !slices.Equal(receiver.Contributors, other.Contributors) {
+		return false
+	}
+	if receiver.Started != other.Started || receiver.Finished != other.Finished {
+		return false
+	}
+	if len(receiver.Layout) != len(other.Layout) {
+		return false
+	}
+	for index, row := range receiver.Layout {
+		if row != other.Layout[index] {
+			return false
+		}
+	}
 	if len(receiver.Moves) != len(other.Moves) {
 		return false
 	}
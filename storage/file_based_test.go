@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"log"
 	"math/rand"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -46,8 +47,8 @@ func TestDecoding(test *testing.T) {
 		log.Printf("Decoding from JSON String produced an error: %s", err)
 		test.FailNow()
 	}
-	move := Move{3, 0}
-	expected := &GameSave{0, 4, 5, 0, []Move{move}}
+	move := Move{X: 3, Y: 0}
+	expected := &GameSave{Seed: 0, Width: 4, Height: 5, MineCount: 0, Moves: []Move{move}}
 	if !expected.EquivalentTo(*decoded) {
 		log.Printf("Decoded GameSave did not produce expected results. Actual: %+v", decoded)
 		test.Fail()
@@ -89,3 +90,111 @@ func TestCodecInverseEquivalence(test *testing.T) {
 	}
 	// Produce seed, width, height, and mine count of failed test.
 }
+
+func TestRecordRoundTrip(test *testing.T) {
+	board, _ := generation.NewBoard(10, 8, 8, 42)
+	testGame := game.NewGame(*board)
+	testGame.Play(game.Move{Coordinate: game.Coordinate{X: 0, Y: 0}, Action: game.Flag, Elapsed: time.Second})
+	testGame.Play(game.Move{Coordinate: game.Coordinate{X: 7, Y: 7}, Action: game.Reveal, Elapsed: 2 * time.Second})
+
+	gameSave := FromGame(*testGame)
+	gameSave.Record(*testGame)
+	if gameSave.Moves[0].Action != game.Flag || gameSave.Moves[1].Action != "" || gameSave.Moves[1].T != 2000 {
+		log.Printf("Moves were not recorded as expected. Actual: %+v", gameSave.Moves)
+		test.Fail()
+	}
+
+	rebuilt := gameSave.ToGame()
+	if !rebuilt.Flagged(game.Coordinate{X: 0, Y: 0}) || rebuilt.Stats() != *gameSave.Stats {
+		log.Printf("Rebuilt game did not match. Actual: %+v Expected: %+v", rebuilt.Stats(), gameSave.Stats)
+		test.Fail()
+	}
+}
+
+func TestReplayTo(test *testing.T) {
+	gameSave := &GameSave{Seed: 42, Width: 8, Height: 8, MineCount: 10, Moves: []Move{
+		{X: 0, Y: 0, Action: game.Flag, T: 1000},
+		{X: 0, Y: 0, Action: game.Flag, T: 2000},
+	}}
+	if !gameSave.ReplayTo(1).Flagged(game.Coordinate{X: 0, Y: 0}) {
+		log.Printf("Expected the first step to have placed a flag.")
+		test.Fail()
+	}
+	if gameSave.ReplayTo(2).Flagged(game.Coordinate{X: 0, Y: 0}) || len(gameSave.ReplayTo(5).Moves) != 2 {
+		log.Printf("Expected the replay to stop after the last move.")
+		test.Fail()
+	}
+}
+
+func TestTopologyRoundTrip(test *testing.T) {
+	board, _ := generation.NewBoard(10, 8, 8, 42)
+	testGame := game.NewGame(*board.OnTopology(generation.Torus))
+	gameSave := FromGame(*testGame)
+	rebuilt := gameSave.ToGame()
+	if gameSave.Topology != generation.Torus || rebuilt.Board.Topology != generation.Torus || !areEqualFields(rebuilt.Board.Field, testGame.Board.Field) {
+		log.Printf("Expected the torus to be rebuilt with its wrapped hints. Actual: %v", rebuilt.Board.Field)
+		test.Fail()
+	}
+	plane := FromGame(*game.NewGame(*board))
+	if plane.EquivalentTo(*gameSave) {
+		log.Printf("Expected saves on different topologies not to be equivalent.")
+		test.Fail()
+	}
+
+	knight, _ := board.WithNeighborhood(generation.Neighborhoods["knight"])
+	knightSave := FromGame(*game.NewGame(*knight))
+	if rebuilt := knightSave.ToGame(); rebuilt.Board.Neighborhood.Name() != "knight" || !areEqualFields(rebuilt.Board.Field, knight.Field) {
+		log.Printf("Expected the knight board to be rebuilt with its hints. Actual: %v", rebuilt.Board.Field)
+		test.Fail()
+	}
+	if knightSave.EquivalentTo(*plane) || ValidateResult(knightSave) == nil {
+		log.Printf("Expected the knight board to differ from the standard one and not be ranked.")
+		test.Fail()
+	}
+}
+
+func TestStackedRoundTrip(test *testing.T) {
+	board, _ := generation.NewMultiMineBoard(30, 3, 8, 8, 42)
+	testGame := game.NewGame(*board)
+	testGame.Play(game.Move{Coordinate: game.Coordinate{X: 0, Y: 0}, Action: game.Flag})
+	testGame.Play(game.Move{Coordinate: game.Coordinate{X: 0, Y: 0}, Action: game.Flag})
+	gameSave := FromGame(*testGame)
+	rebuilt := gameSave.ToGame()
+	if gameSave.MinesPerTile != 3 || !areEqualFields(rebuilt.Board.Counts, board.Counts) || rebuilt.FlagsAt(game.Coordinate{X: 0, Y: 0}) != 2 {
+		log.Printf("Expected the stacked board to be rebuilt with its flags. Actual: %v", rebuilt.Board.Counts)
+		test.Fail()
+	}
+	classic, _ := generation.NewBoard(30, 8, 8, 42)
+	if gameSave.EquivalentTo(*FromGame(*game.NewGame(*classic))) || ValidateResult(gameSave) == nil {
+		log.Printf("Expected the stacked board to differ from the classic one and not be ranked.")
+		test.Fail()
+	}
+}
+
+func TestLayeredRoundTrip(test *testing.T) {
+	board, _ := generation.NewBoard(30, 6, 18, 42)
+	cube, err := board.InLayers(3)
+	if err != nil {
+		log.Printf("Error cutting board into layers: %s", err)
+		test.FailNow()
+	}
+	gameSave := FromGame(*game.NewGame(*cube))
+	rebuilt := gameSave.ToGame()
+	if gameSave.Layers != 3 || rebuilt.Board.Layers != 3 || !areEqualFields(rebuilt.Board.Field, cube.Field) {
+		log.Printf("Expected the layered board to be rebuilt with its hints. Actual: %v", rebuilt.Board.Field)
+		test.Fail()
+	}
+	if gameSave.EquivalentTo(*FromGame(*game.NewGame(*board))) {
+		log.Printf("Expected the layered board to differ from the flat one.")
+		test.Fail()
+	}
+}
+
+func areEqualFields(first [][]int, second [][]int) bool {
+	for y, row := range first {
+		if !slices.Equal(row, second[y]) {
+			return false
+		}
+	}
+	return len(first) == len(second)
+}
@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// GameRecord is the summary of a saved game kept in the games index.
+type GameRecord struct {
+	Name       string  `json:"name"`
+	Player     string  `json:"player"`
+	Difficulty string  `json:"difficulty"`
+	Started    int64   `json:"started"`
+	Finished   int64   `json:"finished,omitempty"`
+	Won        bool    `json:"won"`
+	DurationMs int64   `json:"durationMs,omitempty"`
+	ThreeBV    int     `json:"3bv"`
+	Solved     int     `json:"solved3bv"`
+	Clicks     int     `json:"clicks"`
+	ThreeBVPS  float64 `json:"3bvPerSecond"`
+}
+
+// DifficultyStats aggregates a player's finished games of one difficulty.
+type DifficultyStats struct {
+	Difficulty string `json:"difficulty"`
+	Played     int    `json:"played"`
+	Won        int    `json:"won"`
+	// WinRate is the fraction of played games that were won.
+	WinRate float64 `json:"winRate"`
+	// BestMs is the fastest won game in milliseconds, zero if none were won.
+	BestMs int64 `json:"bestMs"`
+	// AverageThreeBVPS is the mean 3BV/s of won games.
+	AverageThreeBVPS float64 `json:"average3bvPerSecond"`
+	CurrentStreak    int     `json:"currentStreak"`
+	LongestStreak    int     `json:"longestStreak"`
+}
+
+// Returns the index record of a GameSave.
+func (gameSave *GameSave) Summary(name string) GameRecord {
+	record := GameRecord{
+		Name:       name,
+		Player:     gameSave.Player,
+		Difficulty: gameSave.Difficulty,
+		Started:    gameSave.Started,
+		Finished:   gameSave.Finished,
+		Won:        gameSave.Won,
+		DurationMs: gameSave.Duration().Milliseconds(),
+	}
+	if gameSave.Training {
+		record.Difficulty = TrainingPrefix + gameSave.Difficulty
+	}
+	if gameSave.Stats != nil {
+		record.ThreeBV = gameSave.Stats.ThreeBV
+		record.Solved = gameSave.Stats.Solved
+		record.Clicks = gameSave.Stats.Clicks()
+		if record.DurationMs > 0 {
+			record.ThreeBVPS = float64(record.Solved) / (float64(record.DurationMs) / 1000)
+		}
+	}
+	return record
+}
+
+// Adds or updates a game in the games index. Games without an owner are not indexed.
+func (db *DB) IndexGame(name string, gameSave *GameSave) error {
+	if gameSave.Player == "" {
+		return nil
+	}
+	record := gameSave.Summary(name)
+	_, err := db.sql.Exec(`INSERT OR REPLACE INTO games
+		(name, player, difficulty, started, finished, won, duration_ms, three_bv, solved, clicks, three_bv_ps)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.Name, record.Player, record.Difficulty, record.Started, record.Finished, record.Won,
+		record.DurationMs, record.ThreeBV, record.Solved, record.Clicks, record.ThreeBVPS)
+	return err
+}
+
+// Indexes every save on disk that is not yet in the games index. Saves that cannot be read are skipped.
+func (db *DB) IndexSaves() error {
+	paths, err := filepath.Glob(filepath.Join(PathCrumb, "*.sweeper"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".sweeper")
+		var exists int
+		if err := db.sql.QueryRow(`SELECT COUNT(*) FROM games WHERE name = ?`, name).Scan(&exists); err != nil {
+			return err
+		}
+		if exists > 0 {
+			continue
+		}
+		gameSave, err := Load(name)
+		if err != nil || gameSave.Player == "" {
+			continue
+		}
+		if gameSave.Stats == nil {
+			// Saves from before statistics were recorded are rebuilt to find their outcome.
+			if _, err := gameSave.board(); err != nil {
+				continue
+			}
+			game := gameSave.ToGame()
+			gameSave.Record(*game)
+			gameSave.Won = game.Won()
+		}
+		if err := db.IndexGame(name, gameSave); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns a page of a player's games, most recently started first.
+func (db *DB) History(player string, limit int, offset int) ([]GameRecord, error) {
+	rows, err := db.sql.Query(`SELECT name, player, difficulty, started, finished, won, duration_ms, three_bv, solved, clicks, three_bv_ps
+		FROM games WHERE player = ? ORDER BY started DESC, name LIMIT ? OFFSET ?`, player, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := []GameRecord{}
+	for rows.Next() {
+		record := GameRecord{}
+		err := rows.Scan(&record.Name, &record.Player, &record.Difficulty, &record.Started, &record.Finished, &record.Won,
+			&record.DurationMs, &record.ThreeBV, &record.Solved, &record.Clicks, &record.ThreeBVPS)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Returns the names of every game a player owns in the games index.
+func (db *DB) GameNames(player string) ([]string, error) {
+	rows, err := db.sql.Query(`SELECT name FROM games WHERE player = ? ORDER BY name`, player)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// Returns a player's statistics for each difficulty they have finished a game of, ordered by difficulty.
+func (db *DB) PlayerStats(player string) ([]DifficultyStats, error) {
+	rows, err := db.sql.Query(`SELECT difficulty, COUNT(*), SUM(won),
+			COALESCE(MIN(CASE WHEN won THEN duration_ms END), 0),
+			COALESCE(AVG(CASE WHEN won THEN three_bv_ps END), 0)
+		FROM games WHERE player = ? AND finished > 0 GROUP BY difficulty ORDER BY difficulty`, player)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stats := []DifficultyStats{}
+	for rows.Next() {
+		current := DifficultyStats{}
+		err := rows.Scan(&current.Difficulty, &current.Played, &current.Won, &current.BestMs, &current.AverageThreeBVPS)
+		if err != nil {
+			return nil, err
+		}
+		current.WinRate = float64(current.Won) / float64(current.Played)
+		stats = append(stats, current)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for index := range stats {
+		if err := db.streaks(player, &stats[index]); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// Fills in the current and longest win streaks of a difficulty, in the order games finished.
+func (db *DB) streaks(player string, stats *DifficultyStats) error {
+	rows, err := db.sql.Query(`SELECT won FROM games WHERE player = ? AND difficulty = ? AND finished > 0 ORDER BY finished`,
+		player, stats.Difficulty)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var won bool
+		if err := rows.Scan(&won); err != nil {
+			return err
+		}
+		if won {
+			stats.CurrentStreak++
+			stats.LongestStreak = max(stats.LongestStreak, stats.CurrentStreak)
+		} else {
+			stats.CurrentStreak = 0
+		}
+	}
+	return rows.Err()
+}
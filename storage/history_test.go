@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"log"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+)
+
+func indexTestGame(test *testing.T, db *DB, name string, difficulty string, finished int64, won bool) {
+	gameSave := &GameSave{
+		Player:     "sweeper",
+		Difficulty: difficulty,
+		Started:    finished - 10000,
+		Finished:   finished,
+		Won:        won,
+		Stats:      &game.Stats{ThreeBV: 20, Solved: 20, Reveals: 25},
+	}
+	if err := db.IndexGame(name, gameSave); err != nil {
+		log.Printf("Error indexing game: %s", err)
+		test.FailNow()
+	}
+}
+
+func TestPlayerStats(test *testing.T) {
+	db := openTestDB(test)
+	indexTestGame(test, db, "a", "beginner", 100000, true)
+	indexTestGame(test, db, "b", "beginner", 200000, true)
+	indexTestGame(test, db, "c", "beginner", 300000, false)
+	indexTestGame(test, db, "d", "beginner", 400000, true)
+	indexTestGame(test, db, "e", "expert", 500000, false)
+	db.IndexGame("guest", &GameSave{Difficulty: "beginner", Finished: 1, Won: true})
+
+	stats, err := db.PlayerStats("sweeper")
+	if err != nil {
+		log.Printf("Error loading stats: %s", err)
+		test.FailNow()
+	}
+	if len(stats) != 2 {
+		log.Printf("Expected stats for 2 difficulties. Actual: %+v", stats)
+		test.FailNow()
+	}
+	beginner := stats[0]
+	if beginner.Played != 4 || beginner.Won != 3 || beginner.WinRate != 0.75 {
+		log.Printf("Beginner totals were not as expected. Actual: %+v", beginner)
+		test.Fail()
+	}
+	if beginner.BestMs != 10000 || beginner.AverageThreeBVPS != 2 {
+		log.Printf("Beginner best time and 3BV/s were not as expected. Actual: %+v", beginner)
+		test.Fail()
+	}
+	if beginner.CurrentStreak != 1 || beginner.LongestStreak != 2 {
+		log.Printf("Beginner streaks were not as expected. Actual: %+v", beginner)
+		test.Fail()
+	}
+	if stats[1].Won != 0 || stats[1].BestMs != 0 {
+		log.Printf("Expert stats were not as expected. Actual: %+v", stats[1])
+		test.Fail()
+	}
+
+	history, err := db.History("sweeper", 2, 0)
+	if err != nil || len(history) != 2 || history[0].Name != "e" || history[1].Name != "d" {
+		log.Printf("Expected the two most recent games. Actual: %+v Error: %v", history, err)
+		test.Fail()
+	}
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+// Ranking is the order of a leaderboard.
+type Ranking string
+
+const (
+	ByTime      Ranking = "time"
+	ByThreeBVPS Ranking = "3bvps"
+)
+
+// LivesPrefix starts the name of the leaderboard of each difficulty played with lives.
+const LivesPrefix = "lives-"
+
+// finishTolerance is the longest a game may take to be marked finished after its last move.
+const finishTolerance = time.Second
+
+// Result is a player's best validated result on a leaderboard.
+type Result struct {
+	Rank       int     `json:"rank"`
+	Player     string  `json:"player"`
+	Name       string  `json:"game"`
+	DurationMs int64   `json:"durationMs"`
+	ThreeBVPS  float64 `json:"3bvPerSecond"`
+	Finished   int64   `json:"finished"`
+	// LivesUsed is the number of mines hit on the way to a win on a leaderboard played with lives.
+	LivesUsed int `json:"livesUsed,omitempty"`
+}
+
+// Returns the Ranking with a name, defaulting to ByTime.
+func ParseRanking(name string) Ranking {
+	if Ranking(name) == ByThreeBVPS {
+		return ByThreeBVPS
+	}
+	return ByTime
+}
+
+// Results played with lives rank by the lives they used first, so a clean win beats any that hit a mine.
+func (ranking Ranking) orderBy() string {
+	if ranking == ByThreeBVPS {
+		return "lives_used, three_bv_ps DESC, duration_ms"
+	}
+	return "lives_used, duration_ms, three_bv_ps DESC"
+}
+
+// Returns an error describing why a game cannot be ranked, or nil if it is a genuine win of a preset board.
+// The saved moves are replayed from the seed rather than trusting the recorded outcome or statistics.
+func ValidateResult(gameSave *GameSave) error {
+	preset, ok := generation.Presets[gameSave.Difficulty]
+	if !ok {
+		return fmt.Errorf("%q is not a ranked difficulty", gameSave.Difficulty)
+	}
+	if gameSave.Layout != nil || gameSave.Level != "" || gameSave.Daily != "" {
+		return fmt.Errorf("only generated games are ranked")
+	}
+	if gameSave.Coop || gameSave.Training {
+		return fmt.Errorf("cooperative and training games are not ranked")
+	}
+	if gameSave.Lives < 0 || gameSave.Lives > game.MaxLives {
+		return fmt.Errorf("games are ranked with up to %d lives", game.MaxLives)
+	}
+	if gameSave.Topology != generation.Plane || gameSave.Neighborhood != nil || gameSave.MinesPerTile > 1 || gameSave.Layers > 1 {
+		return fmt.Errorf("only flat boards with standard numbers and one mine per tile are ranked")
+	}
+	if gameSave.MineCount != preset.Mines || gameSave.Width != preset.Width || gameSave.Height != preset.Height {
+		return fmt.Errorf("board is not the %s preset", gameSave.Difficulty)
+	}
+	if gameSave.Player == "" {
+		return fmt.Errorf("game has no player")
+	}
+	if gameSave.Bot != "" && gameSave.Player != (Bot{Name: gameSave.Bot}).Player() {
+		return fmt.Errorf("game was not played by bot %s", gameSave.Bot)
+	}
+	if gameSave.Started == 0 || gameSave.Finished < gameSave.Started {
+		return fmt.Errorf("game has no valid start and finish time")
+	}
+	var last int64
+	for index, move := range gameSave.Moves {
+		switch move.Action {
+		case "", game.Flag, game.Chord:
+		default:
+			// Anything beyond the plain actions, such as an undo or a hint, disqualifies a game.
+			return fmt.Errorf("move %d has an unranked action %q", index, move.Action)
+		}
+		if move.T < last {
+			return fmt.Errorf("move %d was made before the move preceding it", index)
+		}
+		last = move.T
+	}
+	duration := gameSave.Duration()
+	if time.Duration(last)*time.Millisecond > duration {
+		return fmt.Errorf("moves were made after the game finished")
+	}
+	if duration-time.Duration(last)*time.Millisecond > finishTolerance {
+		return fmt.Errorf("game finished long after its last move")
+	}
+	replay := gameSave.ToGame()
+	if len(replay.Moves) != len(gameSave.Moves) {
+		return fmt.Errorf("moves do not replay on this board")
+	}
+	if !replay.Won() {
+		return fmt.Errorf("moves do not win the game")
+	}
+	return nil
+}
+
+// Returns the leaderboard a game is ranked on: its difficulty, or the bot leaderboard of it for games played by bots
+// and the lives leaderboard of it for games played with lives.
+func (gameSave *GameSave) Leaderboard() string {
+	if gameSave.Bot != "" {
+		return BotLeaderboard(gameSave.Difficulty)
+	}
+	if gameSave.Lives > 0 {
+		return LivesLeaderboard(gameSave.Difficulty)
+	}
+	return gameSave.Difficulty
+}
+
+// Returns the leaderboard results of a difficulty played with lives are ranked on, kept apart from classic results.
+func LivesLeaderboard(difficulty string) string {
+	return LivesPrefix + difficulty
+}
+
+// Validates a finished game and adds it to its leaderboard.
+func (db *DB) SubmitResult(name string, gameSave *GameSave) error {
+	if err := ValidateResult(gameSave); err != nil {
+		return err
+	}
+	duration := gameSave.Duration()
+	replay := gameSave.ToGame()
+	stats := replay.Stats()
+	var threeBVPS float64
+	if duration > 0 {
+		threeBVPS = float64(stats.ThreeBV) / duration.Seconds()
+	}
+	_, err := db.sql.Exec(`INSERT OR REPLACE INTO leaderboard (name, player, difficulty, duration_ms, three_bv_ps, finished, lives_used)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		name, gameSave.Player, gameSave.Leaderboard(), duration.Milliseconds(), threeBVPS, gameSave.Finished, len(replay.Hits))
+	return err
+}
+
+// ranked selects each player's best result of a difficulty with its rank and position on the leaderboard.
+func (ranking Ranking) ranked() string {
+	order := ranking.orderBy()
+	return `WITH best AS (
+			SELECT name, player, duration_ms, three_bv_ps, finished, lives_used,
+				ROW_NUMBER() OVER (PARTITION BY player ORDER BY ` + order + `, finished) AS player_rank
+			FROM leaderboard WHERE difficulty = ?
+		), ranked AS (
+			SELECT name, player, duration_ms, three_bv_ps, finished, lives_used,
+				RANK() OVER (ORDER BY ` + order + `) AS rank,
+				ROW_NUMBER() OVER (ORDER BY ` + order + `, finished) AS position
+			FROM best WHERE player_rank = 1
+		)`
+}
+
+// Returns the best limit results of a difficulty.
+func (db *DB) TopResults(difficulty string, ranking Ranking, limit int) ([]Result, error) {
+	return db.queryResults(ranking.ranked()+`
+		SELECT rank, player, name, duration_ms, three_bv_ps, finished, lives_used FROM ranked ORDER BY position LIMIT ?`,
+		difficulty, limit)
+}
+
+// Returns a player's result of a difficulty along with up to radius results either side of it.
+// The slice is empty if the player has no result.
+func (db *DB) ResultsAround(difficulty string, ranking Ranking, player string, radius int) ([]Result, error) {
+	return db.queryResults(ranking.ranked()+`, mine AS (SELECT position FROM ranked WHERE player = ?)
+		SELECT rank, player, name, duration_ms, three_bv_ps, finished, lives_used FROM ranked, mine
+		WHERE ranked.position BETWEEN mine.position - ? AND mine.position + ? ORDER BY ranked.position`,
+		difficulty, player, radius, radius)
+}
+
+func (db *DB) queryResults(query string, args ...any) ([]Result, error) {
+	rows, err := db.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []Result{}
+	for rows.Next() {
+		result := Result{}
+		err := rows.Scan(&result.Rank, &result.Player, &result.Name, &result.DurationMs, &result.ThreeBVPS, &result.Finished,
+			&result.LivesUsed)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
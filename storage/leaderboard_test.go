@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"database/sql"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+// Returns a beginner save won by revealing every safe tile, one move every step milliseconds.
+func wonTestSave(test *testing.T, player string, seed int64, step int64) *GameSave {
+	return livesTestSave(test, player, seed, step, 0, 0)
+}
+
+// Returns a beginner save played with lives that hits a number of mines before it is won like wonTestSave.
+func livesTestSave(test *testing.T, player string, seed int64, step int64, lives int, hits int) *GameSave {
+	board, err := generation.NewBoard(10, 8, 8, seed)
+	if err != nil {
+		log.Printf("Error generating board: %s", err)
+		test.FailNow()
+	}
+	newGame := game.NewGame(*board)
+	newGame.Lives = lives
+	var elapsed int64
+	for y, row := range board.Field {
+		for x, value := range row {
+			if value == -9 && len(newGame.Hits) < hits {
+				elapsed += step
+				newGame.Play(game.Move{Coordinate: game.Coordinate{X: x, Y: y}, Elapsed: time.Duration(elapsed) * time.Millisecond})
+			}
+		}
+	}
+	for y, row := range board.Field {
+		for x, value := range row {
+			if value != -9 && newGame.Revealed[y][x] < 0 {
+				elapsed += step
+				newGame.Play(game.Move{Coordinate: game.Coordinate{X: x, Y: y}, Elapsed: time.Duration(elapsed) * time.Millisecond})
+			}
+		}
+	}
+	gameSave := FromGame(*newGame)
+	gameSave.Player = player
+	gameSave.Difficulty = "beginner"
+	gameSave.Started = 1000000
+	gameSave.Finished = gameSave.Started + elapsed
+	gameSave.Won = true
+	return gameSave
+}
+
+func TestValidateResult(test *testing.T) {
+	if err := ValidateResult(wonTestSave(test, "sweeper", 1, 100)); err != nil {
+		log.Printf("Expected won game to be valid. Actual: %s", err)
+		test.Fail()
+	}
+	tampered := map[string]func(*GameSave){
+		"unranked difficulty": func(save *GameSave) { save.Difficulty = "custom" },
+		"unfinished":          func(save *GameSave) { save.Moves = save.Moves[:len(save.Moves)-1] },
+		"undo":                func(save *GameSave) { save.Moves[1].Action = "undo" },
+		"out of order":        func(save *GameSave) { save.Moves[1].T = 0 },
+		"after finish":        func(save *GameSave) { save.Finished -= 50 },
+		"late finish":         func(save *GameSave) { save.Finished += 5000 },
+		"wrong board":         func(save *GameSave) { save.Seed = 2 },
+		"torus":               func(save *GameSave) { save.Topology = generation.Torus },
+		"layered":             func(save *GameSave) { save.Layers = 2 },
+		"too many lives":      func(save *GameSave) { save.Lives = game.MaxLives + 1 },
+		"training":            func(save *GameSave) { save.Training = true },
+	}
+	for name, tamper := range tampered {
+		gameSave := wonTestSave(test, "sweeper", 1, 100)
+		tamper(gameSave)
+		if ValidateResult(gameSave) == nil {
+			log.Printf("Expected %s game to be rejected.", name)
+			test.Fail()
+		}
+	}
+}
+
+func TestLeaderboard(test *testing.T) {
+	db := openTestDB(test)
+	submissions := []struct {
+		name   string
+		player string
+		step   int64
+	}{
+		{"slow", "alice", 300},
+		{"fast", "alice", 100},
+		{"middle", "bob", 200},
+		{"slowest", "carol", 400},
+	}
+	for _, submission := range submissions {
+		if err := db.SubmitResult(submission.name, wonTestSave(test, submission.player, 1, submission.step)); err != nil {
+			log.Printf("Error submitting result: %s", err)
+			test.FailNow()
+		}
+	}
+	cheat := wonTestSave(test, "mallory", 1, 10)
+	cheat.Moves = cheat.Moves[:1]
+	if db.SubmitResult("cheat", cheat) == nil {
+		log.Printf("Expected unfinished game to be rejected.")
+		test.Fail()
+	}
+
+	top, err := db.TopResults("beginner", ByTime, 2)
+	if err != nil {
+		log.Printf("Error loading leaderboard: %s", err)
+		test.FailNow()
+	}
+	if len(top) != 2 || top[0].Name != "fast" || top[1].Player != "bob" || top[1].Rank != 2 {
+		log.Printf("Expected each player's best result ranked by time. Actual: %+v", top)
+		test.Fail()
+	}
+
+	around, err := db.ResultsAround("beginner", ByThreeBVPS, "carol", 1)
+	if err != nil {
+		log.Printf("Error loading leaderboard: %s", err)
+		test.FailNow()
+	}
+	if len(around) != 2 || around[0].Player != "bob" || around[1].Player != "carol" || around[1].Rank != 3 {
+		log.Printf("Expected carol's result with the one above it. Actual: %+v", around)
+		test.Fail()
+	}
+	none, _ := db.ResultsAround("beginner", ByTime, "mallory", 1)
+	if len(none) != 0 {
+		log.Printf("Expected no results around a player without a result. Actual: %+v", none)
+		test.Fail()
+	}
+}
+
+func TestLivesLeaderboard(test *testing.T) {
+	db := openTestDB(test)
+	reckless := livesTestSave(test, "alice", 1, 100, 3, 2)
+	careful := livesTestSave(test, "bob", 1, 300, 3, 0)
+	if reckless.LivesUsed != 2 || reckless.Leaderboard() != LivesLeaderboard("beginner") {
+		log.Printf("Expected the game to use 2 lives on the lives leaderboard. Actual: %d on %s", reckless.LivesUsed, reckless.Leaderboard())
+		test.FailNow()
+	}
+	for name, gameSave := range map[string]*GameSave{"reckless": reckless, "careful": careful} {
+		if err := db.SubmitResult(name, gameSave); err != nil {
+			log.Printf("Error submitting result: %s", err)
+			test.FailNow()
+		}
+	}
+	top, err := db.TopResults(LivesLeaderboard("beginner"), ByTime, 5)
+	if err != nil {
+		log.Printf("Error loading leaderboard: %s", err)
+		test.FailNow()
+	}
+	if len(top) != 2 || top[0].Player != "bob" || top[1].Player != "alice" || top[1].LivesUsed != 2 {
+		log.Printf("Expected the win without hits to rank above the faster one with hits. Actual: %+v", top)
+		test.Fail()
+	}
+	if classic, _ := db.TopResults("beginner", ByTime, 5); len(classic) != 0 {
+		log.Printf("Expected games with lives to stay off the classic leaderboard. Actual: %+v", classic)
+		test.Fail()
+	}
+}
+
+func TestLivesColumnAdded(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "old.db")
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		log.Printf("Error opening database: %s", err)
+		test.FailNow()
+	}
+	_, err = old.Exec(`CREATE TABLE leaderboard (name TEXT PRIMARY KEY, player TEXT NOT NULL, difficulty TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL, three_bv_ps REAL NOT NULL, finished INTEGER NOT NULL)`)
+	old.Close()
+	if err != nil {
+		log.Printf("Error creating the old leaderboard: %s", err)
+		test.FailNow()
+	}
+	db, err := Open(path)
+	if err != nil {
+		log.Printf("Expected a database from before lives to open. Error: %s", err)
+		test.FailNow()
+	}
+	defer db.Close()
+	if err := db.SubmitResult("won", wonTestSave(test, "alice", 1, 100)); err != nil {
+		log.Printf("Expected results to be ranked after the lives column was added. Error: %s", err)
+		test.Fail()
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var ProgressCrumb = filepath.Join(PathCrumb, "progress")
+
+var playerPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Progress stores the campaign levels a player has completed, keyed by "pack/level".
+type Progress struct {
+	Completed map[string]bool `json:"completed"`
+}
+
+// Returns true if a player name is safe to use as part of a storage path.
+func ValidPlayer(player string) bool {
+	return playerPattern.MatchString(player)
+}
+
+// Marks a campaign level as completed.
+func (progress *Progress) Complete(key string) {
+	if progress.Completed == nil {
+		progress.Completed = map[string]bool{}
+	}
+	progress.Completed[key] = true
+}
+
+// Loads the Progress of a player. A player without saved progress receives an empty Progress.
+func LoadProgress(player string) (*Progress, error) {
+	if !ValidPlayer(player) {
+		return &Progress{}, fmt.Errorf("invalid player name: %q", player)
+	}
+	buffer, err := os.ReadFile(filepath.Join(ProgressCrumb, player+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Progress{Completed: map[string]bool{}}, nil
+	}
+	if err != nil {
+		return &Progress{}, err
+	}
+	progress := Progress{}
+	if err := json.Unmarshal(buffer, &progress); err != nil {
+		return &Progress{}, err
+	}
+	if progress.Completed == nil {
+		progress.Completed = map[string]bool{}
+	}
+	return &progress, nil
+}
+
+// Saves the Progress of a player.
+func (progress *Progress) Save(player string) error {
+	if !ValidPlayer(player) {
+		return fmt.Errorf("invalid player name: %q", player)
+	}
+	if err := os.MkdirAll(ProgressCrumb, 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(ProgressCrumb, player+".json"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(progress)
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"log"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgressRoundTrip(test *testing.T) {
+	ProgressCrumb = filepath.Join(test.TempDir(), "progress")
+
+	progress, err := LoadProgress("player_1")
+	if err != nil || len(progress.Completed) != 0 {
+		log.Printf("Expected empty progress for a new player. Actual: %+v Error: %v", progress, err)
+		test.FailNow()
+	}
+	progress.Complete("basics/first-steps")
+	if err := progress.Save("player_1"); err != nil {
+		log.Printf("Error saving progress: %s", err)
+		test.FailNow()
+	}
+
+	loaded, err := LoadProgress("player_1")
+	if err != nil || !loaded.Completed["basics/first-steps"] {
+		log.Printf("Saved progress did not load. Actual: %+v Error: %v", loaded, err)
+		test.Fail()
+	}
+}
+
+func TestProgressInvalidPlayer(test *testing.T) {
+	if _, err := LoadProgress("../escape"); err == nil {
+		log.Printf("Expected LoadProgress to reject a player name containing a path.")
+		test.Fail()
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+// RaceResult is one player's placing in a finished race.
+type RaceResult struct {
+	Race string `json:"race"`
+	// Player identifies the player to the server, so it is never sent to clients.
+	Player string `json:"-"`
+	Rank   int    `json:"rank"`
+	Game   string `json:"game"`
+	Won    bool   `json:"won"`
+	// DurationMs is the time from the start of the race until the player won, lost or left.
+	DurationMs int64   `json:"durationMs"`
+	Cleared    float64 `json:"cleared"`
+}
+
+// Stores the results of a race, replacing any recorded before.
+func (db *DB) RecordRace(results []RaceResult) error {
+	transaction, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer transaction.Rollback()
+	for _, result := range results {
+		_, err := transaction.Exec(`INSERT OR REPLACE INTO race_results (race, player, rank, game, won, duration_ms, cleared)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			result.Race, result.Player, result.Rank, result.Game, result.Won, result.DurationMs, result.Cleared)
+		if err != nil {
+			return err
+		}
+	}
+	return transaction.Commit()
+}
+
+// Returns the results of a race in rank order, or an empty slice if it was never recorded.
+func (db *DB) RaceResults(race string) ([]RaceResult, error) {
+	rows, err := db.sql.Query(`SELECT race, player, rank, game, won, duration_ms, cleared
+		FROM race_results WHERE race = ? ORDER BY rank`, race)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []RaceResult{}
+	for rows.Next() {
+		result := RaceResult{}
+		err := rows.Scan(&result.Race, &result.Player, &result.Rank, &result.Game, &result.Won, &result.DurationMs, &result.Cleared)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
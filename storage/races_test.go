@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"log"
+	"testing"
+)
+
+func TestRaceResults(test *testing.T) {
+	db := openTestDB(test)
+	err := db.RecordRace([]RaceResult{
+		{Race: "race", Player: "bob", Rank: 2, Game: "b", Cleared: 0.5, DurationMs: 3000},
+		{Race: "race", Player: "alice", Rank: 1, Game: "a", Won: true, Cleared: 1, DurationMs: 2000},
+	})
+	if err != nil {
+		log.Printf("Error recording race: %s", err)
+		test.FailNow()
+	}
+	results, err := db.RaceResults("race")
+	if err != nil || len(results) != 2 || results[0].Player != "alice" || !results[0].Won || results[1].Cleared != 0.5 {
+		log.Printf("Expected results in rank order. Actual: %+v %v", results, err)
+		test.Fail()
+	}
+	if missing, _ := db.RaceResults("missing"); len(missing) != 0 {
+		log.Printf("Expected no results for an unknown race. Actual: %+v", missing)
+		test.Fail()
+	}
+}
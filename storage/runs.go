@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRunNotFound = errors.New("run does not exist")
+
+// Run is a player's time attack or endless run: games played one after another and scored together.
+type Run struct {
+	Name string `json:"name"`
+	// Player identifies the player to the server, so it is never sent to clients.
+	Player     string `json:"-"`
+	Mode       string `json:"mode"`
+	Difficulty string `json:"difficulty"`
+	// Seed decides every board of the run, so it is never sent to clients.
+	Seed int64 `json:"-"`
+	// Stage counts the games played before the current one, named Game.
+	Stage int    `json:"stage"`
+	Game  string `json:"game"`
+	// Score is the number of boards or regions cleared, the last of them ScoredMs after the run started.
+	Score    int   `json:"score"`
+	ScoredMs int64 `json:"scoredMs"`
+	// Started, Ends and Finished are Unix times in milliseconds. Ends is zero for runs without a time limit.
+	Started  int64 `json:"started"`
+	Ends     int64 `json:"ends,omitempty"`
+	Finished int64 `json:"finished,omitempty"`
+}
+
+// Returns true if the run has finished or its time has run out.
+func (run *Run) Over(now time.Time) bool {
+	return run.Finished != 0 || (run.Ends != 0 && now.UnixMilli() >= run.Ends)
+}
+
+// Returns the time left in the run, zero for runs that are over or have no time limit.
+func (run *Run) Remaining(now time.Time) time.Duration {
+	if run.Ends == 0 || run.Over(now) {
+		return 0
+	}
+	return time.Duration(run.Ends-now.UnixMilli()) * time.Millisecond
+}
+
+// Stores a run, replacing any saved before under its name.
+func (db *DB) SaveRun(run *Run) error {
+	_, err := db.sql.Exec(`INSERT OR REPLACE INTO runs
+		(name, player, mode, difficulty, seed, stage, game, score, scored_ms, started, ends, finished)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Name, run.Player, run.Mode, run.Difficulty, run.Seed, run.Stage, run.Game, run.Score, run.ScoredMs,
+		run.Started, run.Ends, run.Finished)
+	return err
+}
+
+// Returns the run with a name, or ErrRunNotFound.
+func (db *DB) LoadRun(name string) (*Run, error) {
+	runs, err := db.queryRuns(`SELECT name, player, mode, difficulty, seed, stage, game, score, scored_ms, started, ends, finished
+		FROM runs WHERE name = ?`, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, ErrRunNotFound
+	}
+	return &runs[0], nil
+}
+
+// Returns each player's best finished run of a mode on a difficulty, highest scores first and the quickest to
+// reach them among equal scores, up to limit runs. Runs whose time ran out by now count as finished.
+func (db *DB) TopRuns(mode string, difficulty string, limit int, now time.Time) ([]Run, error) {
+	return db.queryRuns(`WITH best AS (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY player ORDER BY score DESC, scored_ms, started) AS player_rank
+			FROM runs WHERE mode = ? AND difficulty = ? AND score > 0 AND (finished != 0 OR (ends != 0 AND ends <= ?))
+		)
+		SELECT name, player, mode, difficulty, seed, stage, game, score, scored_ms, started, ends, finished
+		FROM best WHERE player_rank = 1 ORDER BY score DESC, scored_ms, started LIMIT ?`,
+		mode, difficulty, now.UnixMilli(), limit)
+}
+
+func (db *DB) queryRuns(query string, args ...any) ([]Run, error) {
+	rows, err := db.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	runs := []Run{}
+	for rows.Next() {
+		run := Run{}
+		err := rows.Scan(&run.Name, &run.Player, &run.Mode, &run.Difficulty, &run.Seed, &run.Stage, &run.Game,
+			&run.Score, &run.ScoredMs, &run.Started, &run.Ends, &run.Finished)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
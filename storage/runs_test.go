@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuns(test *testing.T) {
+	db := openTestDB(test)
+	now := time.UnixMilli(1000000)
+	runs := []Run{
+		{Name: "short", Player: "alice", Score: 3, ScoredMs: 90000, Finished: 1},
+		{Name: "best", Player: "alice", Score: 5, ScoredMs: 170000, Finished: 1},
+		{Name: "quick", Player: "bob", Score: 5, ScoredMs: 120000, Ends: now.UnixMilli()},
+		{Name: "empty", Player: "carol", Finished: 1},
+		{Name: "playing", Player: "erin", Score: 8, Ends: now.UnixMilli() + 1},
+		{Name: "other", Player: "dave", Difficulty: "expert", Score: 9, Finished: 1},
+	}
+	for _, run := range runs {
+		run.Mode = "timeattack"
+		if run.Difficulty == "" {
+			run.Difficulty = "beginner"
+		}
+		if err := db.SaveRun(&run); err != nil {
+			log.Printf("Error saving run: %s", err)
+			test.FailNow()
+		}
+	}
+	top, err := db.TopRuns("timeattack", "beginner", 5, now)
+	if err != nil {
+		log.Printf("Error loading runs: %s", err)
+		test.FailNow()
+	}
+	if len(top) != 2 || top[0].Name != "quick" || top[1].Name != "best" {
+		log.Printf("Expected each player's best finished run, quickest first among equal scores. Actual: %+v", top)
+		test.Fail()
+	}
+
+	loaded, err := db.LoadRun("short")
+	if err != nil || loaded.Score != 3 || loaded.Player != "alice" {
+		log.Printf("Expected the run to load as saved. Actual: %+v, %v", loaded, err)
+		test.Fail()
+	}
+	if encoded, _ := json.Marshal(Run{Name: "secret", Seed: 12345}); strings.Contains(string(encoded), "12345") {
+		log.Printf("Expected the seed deciding the run's boards to stay out of its JSON. Actual: %s", encoded)
+		test.Fail()
+	}
+	if encoded, _ := json.Marshal(Run{Name: "secret", Player: "alice"}); strings.Contains(string(encoded), "alice") {
+		log.Printf("Expected the player of the run to stay out of its JSON. Actual: %s", encoded)
+		test.Fail()
+	}
+	if _, err := db.LoadRun("missing"); !errors.Is(err, ErrRunNotFound) {
+		log.Printf("Expected a missing run not to be found. Actual: %v", err)
+		test.Fail()
+	}
+}
+
+func TestRunOver(test *testing.T) {
+	now := time.UnixMilli(1000000)
+	timed := Run{Started: now.UnixMilli(), Ends: now.Add(time.Minute).UnixMilli()}
+	if timed.Over(now) || timed.Remaining(now) != time.Minute {
+		log.Printf("Expected a minute left in the run. Actual: %s", timed.Remaining(now))
+		test.Fail()
+	}
+	if later := now.Add(2 * time.Minute); !timed.Over(later) || timed.Remaining(later) != 0 {
+		log.Printf("Expected the run to be over once its time ran out.")
+		test.Fail()
+	}
+	endless := Run{Started: now.UnixMilli()}
+	if endless.Over(now.Add(time.Hour)) {
+		log.Printf("Expected runs without a time limit to go on until they finish.")
+		test.Fail()
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var DatabasePath = filepath.Join(PathCrumb, "gominesweeper.db")
+
+// schema creates every table used by DB. Each statement must be safe to run against an existing database.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS accounts (
+		username      TEXT PRIMARY KEY,
+		password_hash BLOB NOT NULL,
+		created       INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS sessions (
+		token    TEXT PRIMARY KEY,
+		username TEXT NOT NULL REFERENCES accounts(username) ON DELETE CASCADE,
+		expires  INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS sessions_username ON sessions(username)`,
+	`CREATE TABLE IF NOT EXISTS games (
+		name        TEXT PRIMARY KEY,
+		player      TEXT NOT NULL,
+		difficulty  TEXT NOT NULL,
+		started     INTEGER NOT NULL,
+		finished    INTEGER NOT NULL,
+		won         INTEGER NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		three_bv    INTEGER NOT NULL,
+		solved      INTEGER NOT NULL,
+		clicks      INTEGER NOT NULL,
+		three_bv_ps REAL NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS games_player ON games(player, started)`,
+	`CREATE TABLE IF NOT EXISTS leaderboard (
+		name        TEXT PRIMARY KEY,
+		player      TEXT NOT NULL,
+		difficulty  TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		three_bv_ps REAL NOT NULL,
+		finished    INTEGER NOT NULL,
+		lives_used  INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS leaderboard_difficulty ON leaderboard(difficulty, player)`,
+	`CREATE TABLE IF NOT EXISTS race_results (
+		race        TEXT NOT NULL,
+		player      TEXT NOT NULL,
+		rank        INTEGER NOT NULL,
+		game        TEXT NOT NULL,
+		won         INTEGER NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		cleared     REAL NOT NULL,
+		PRIMARY KEY (race, player)
+	)`,
+	`CREATE TABLE IF NOT EXISTS bots (
+		name       TEXT PRIMARY KEY,
+		owner      TEXT NOT NULL REFERENCES accounts(username) ON DELETE CASCADE,
+		token_hash TEXT NOT NULL UNIQUE,
+		created    INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS bots_owner ON bots(owner)`,
+	`CREATE TABLE IF NOT EXISTS runs (
+		name       TEXT PRIMARY KEY,
+		player     TEXT NOT NULL,
+		mode       TEXT NOT NULL,
+		difficulty TEXT NOT NULL,
+		seed       INTEGER NOT NULL,
+		stage      INTEGER NOT NULL,
+		game       TEXT NOT NULL,
+		score      INTEGER NOT NULL,
+		scored_ms  INTEGER NOT NULL,
+		started    INTEGER NOT NULL,
+		ends       INTEGER NOT NULL,
+		finished   INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS runs_mode ON runs(mode, difficulty, player)`,
+}
+
+// column is a column added to a table after it was first created.
+type column struct {
+	table      string
+	name       string
+	definition string
+}
+
+// columns are added to databases created before they were part of the schema.
+var columns = []column{
+	{"leaderboard", "lives_used", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// DB stores accounts, sessions and other relational data in SQLite.
+type DB struct {
+	sql *sql.DB
+}
+
+// Opens the SQLite database at a path, creating it and its tables if they do not exist.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	database, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, so serialise access through one connection.
+	database.SetMaxOpenConns(1)
+	for _, statement := range schema {
+		if _, err := database.Exec(statement); err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+	for _, added := range columns {
+		if err := addColumn(database, added); err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+	return &DB{database}, nil
+}
+
+// Adds a column to its table unless the table already has it.
+func addColumn(database *sql.DB, added column) error {
+	var count int
+	err := database.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, added.table, added.name).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = database.Exec(`ALTER TABLE ` + added.table + ` ADD COLUMN ` + added.name + ` ` + added.definition)
+	return err
+}
+
+func (db *DB) Close() error {
+	return db.sql.Close()
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/solver"
+)
+
+// TrainingPrefix starts the difficulty training games are indexed under, keeping them out of each difficulty's
+// statistics.
+const TrainingPrefix = "training-"
+
+// Reviews a reveal about to be made in a training game, recording it as a mistake if it is one. Returns the
+// mistake, or nil if the reveal was sound or the game is not a training game.
+func (gameSave *GameSave) Review(played game.Game, move game.Move) *solver.Mistake {
+	if !gameSave.Training || move.Action != game.Reveal || played.Over() || !played.Board.IsInRange(move.Y, move.X) ||
+		played.Flagged(move.Coordinate) {
+		return nil
+	}
+	mistake := solver.Review(solver.FromGame(played), move.Coordinate)
+	if mistake != nil {
+		mistake.Move = len(played.Moves)
+		gameSave.Mistakes = append(gameSave.Mistakes, *mistake)
+	}
+	return mistake
+}
+
+var ErrNoMistake = errors.New("training games can only be rewound to before a mistake")
+
+// Rewinds a training game to before one of its moves so it can be played again from there, dropping the moves
+// and mistakes from it on. The move must be a recorded mistake or come before one. Returns the rewound Game.
+func (gameSave *GameSave) Rewind(step int) (*game.Game, error) {
+	if step < 0 || !slices.ContainsFunc(gameSave.Mistakes, func(mistake solver.Mistake) bool {
+		return mistake.Move >= step && mistake.Move < len(gameSave.Moves)
+	}) {
+		return nil, ErrNoMistake
+	}
+	played := gameSave.ReplayTo(step)
+	gameSave.Record(*played)
+	gameSave.Mistakes = slices.DeleteFunc(gameSave.Mistakes, func(mistake solver.Mistake) bool {
+		return mistake.Move >= step
+	})
+	gameSave.Finished = 0
+	gameSave.Won = false
+	gameSave.Retries++
+	return played, nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"log"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/solver"
+)
+
+func TestTrainingRewind(test *testing.T) {
+	board, err := generation.FromLayout([]string{
+		"*.*.",
+		"....",
+		"....",
+		"....",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	played := game.NewGame(*board)
+	gameSave := FromGame(*played)
+	gameSave.Layout = board.Layout()
+	gameSave.Difficulty = "custom"
+	gameSave.Training = true
+
+	// Opening the bottom leaves the top row hidden, where the 1 and 2 below it prove 0_2 is a mine.
+	for _, move := range []game.Move{
+		{Coordinate: game.Coordinate{X: 3, Y: 3}, Action: game.Reveal},
+		{Coordinate: game.Coordinate{X: 2, Y: 0}, Action: game.Reveal},
+	} {
+		gameSave.Review(*played, move)
+		played.Play(move)
+	}
+	gameSave.Record(*played)
+	if !played.Lost() || len(gameSave.Mistakes) != 1 {
+		log.Printf("Expected the mine hit to be the only mistake. Actual: %+v", gameSave.Mistakes)
+		test.FailNow()
+	}
+	if mistake := gameSave.Mistakes[0]; mistake.Move != 1 || !mistake.Mine || mistake.Technique != solver.Subset {
+		log.Printf("Expected the second move to miss a subset deduction of a mine. Actual: %+v", mistake)
+		test.Fail()
+	}
+
+	if _, err := gameSave.Rewind(len(gameSave.Moves)); err == nil {
+		log.Printf("Expected rewinding to after the last move to be rejected.")
+		test.Fail()
+	}
+	rewound, err := gameSave.Rewind(gameSave.Mistakes[0].Move)
+	if err != nil || rewound.Over() || len(gameSave.Moves) != 1 || len(gameSave.Mistakes) != 0 || gameSave.Retries != 1 {
+		log.Printf("Expected the game to be rewound to before the mistake. Actual: %d moves, %+v", len(gameSave.Moves), gameSave.Mistakes)
+		test.Fail()
+	}
+	if _, err := gameSave.Rewind(0); err == nil {
+		log.Printf("Expected rewinding a game without mistakes left to be rejected.")
+		test.Fail()
+	}
+	if record := gameSave.Summary("training"); record.Difficulty != TrainingPrefix+"custom" {
+		log.Printf("Expected training games to be indexed apart. Actual: %s", record.Difficulty)
+		test.Fail()
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/versus"
+)
+
+// MatchMove is a tile picked during a flag battle.
+type MatchMove struct {
+	X    int `json:"x"`
+	Y    int `json:"y"`
+	Seat int `json:"seat"`
+	// T is the time since the match started in milliseconds.
+	T int64 `json:"t,omitempty"`
+}
+
+// MatchSave stores a flag battle as its board, its players and the tiles they picked in order.
+type MatchSave struct {
+	Seed      int64       `json:"seed"`
+	Width     int         `json:"width"`
+	Height    int         `json:"height"`
+	MineCount int         `json:"mineCount"`
+	Players   []string    `json:"players"`
+	Moves     []MatchMove `json:"moves"`
+	// Started and Finished are Unix times in milliseconds. Matches start when the second player joins.
+	Started  int64 `json:"started,omitempty"`
+	Finished int64 `json:"finished,omitempty"`
+}
+
+// Records the players and moves of a Match into an existing MatchSave, keeping the rest of its fields.
+func (matchSave *MatchSave) Record(match versus.Match) {
+	matchSave.Players = match.Players
+	moves := []MatchMove{}
+	for _, move := range match.Moves {
+		moves = append(moves, MatchMove{X: move.X, Y: move.Y, Seat: move.Seat, T: move.Elapsed.Milliseconds()})
+	}
+	matchSave.Moves = moves
+}
+
+// Recreates and returns a Match from a MatchSave by replaying its moves.
+func (matchSave *MatchSave) ToMatch() *versus.Match {
+	board, err := generation.NewBoard(matchSave.MineCount, matchSave.Width, matchSave.Height, matchSave.Seed)
+	if err != nil {
+		log.Fatalf("Encountered an error in converting MatchSave to Match: %s", err)
+	}
+	match := versus.NewMatch(*board, "")
+	match.Players = matchSave.Players
+	for _, move := range matchSave.Moves {
+		replayed := versus.Move{
+			Coordinate: game.Coordinate{X: move.X, Y: move.Y},
+			Elapsed:    time.Duration(move.T) * time.Millisecond,
+		}
+		if move.Seat < 0 || move.Seat >= len(match.Players) {
+			continue
+		}
+		if _, err := match.Play(match.Players[move.Seat], replayed); err != nil {
+			log.Printf("Skipping invalid saved match move %+v: %s", move, err)
+		}
+	}
+	return match
+}
+
+func (matchSave *MatchSave) Save(name string) error {
+	if err := os.MkdirAll(PathCrumb, 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(PathCrumb, name+".versus"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(matchSave)
+}
+
+func LoadMatch(name string) (*MatchSave, error) {
+	file, err := os.Open(filepath.Join(PathCrumb, name+".versus"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	matchSave := MatchSave{}
+	if err := json.NewDecoder(file).Decode(&matchSave); err != nil {
+		return nil, err
+	}
+	return &matchSave, nil
+}
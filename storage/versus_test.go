@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/versus"
+)
+
+func TestMatchRoundTrip(test *testing.T) {
+	matchSave := &MatchSave{Seed: 42, Width: 8, Height: 8, MineCount: 10, Players: []string{"alice", "bob"}}
+	match := matchSave.ToMatch()
+	// Pick tiles in order, whoever's turn it is, until both players have moved.
+	for y := 0; y < 8 && match.Turn == 0; y++ {
+		for x := 0; x < 8 && match.Turn == 0; x++ {
+			match.Play("alice", versus.Move{Coordinate: game.Coordinate{X: x, Y: y}, Elapsed: time.Second})
+		}
+	}
+	match.Play("bob", versus.Move{Coordinate: game.Coordinate{X: 7, Y: 7}, Elapsed: 2 * time.Second})
+
+	matchSave.Record(*match)
+	if len(matchSave.Moves) != len(match.Moves) || matchSave.Moves[len(matchSave.Moves)-1].T != 2000 {
+		log.Printf("Moves were not recorded as expected. Actual: %+v", matchSave.Moves)
+		test.Fail()
+	}
+	rebuilt := matchSave.ToMatch()
+	if rebuilt.Scores != match.Scores || rebuilt.Turn != match.Turn || len(rebuilt.Moves) != len(match.Moves) {
+		log.Printf("Rebuilt match did not match. Actual: %+v Expected: %+v", rebuilt, match)
+		test.Fail()
+	}
+}
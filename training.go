@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+// Rewinds a training game to before one of its moves, letting the owner retry from before a mistake.
+func rewindHandler(w http.ResponseWriter, req *http.Request) {
+	gameCtx := req.Context().Value(GameIDString).(string)
+	unlock := lockGame(gameCtx)
+	defer unlock()
+	gameSave, err := storage.Load(gameCtx)
+	if err != nil {
+		http.Error(w, "game not found", 404)
+		return
+	}
+	if !ownsGame(w, req, gameSave) {
+		http.Error(w, errNotOwner.Error(), 403)
+		return
+	}
+	if !gameSave.Training {
+		http.Error(w, "only training games can be rewound", 409)
+		return
+	}
+	step, err := strconv.Atoi(req.FormValue("move"))
+	if err != nil {
+		http.Error(w, "a valid move to rewind to was not sent", 400)
+		return
+	}
+	played, err := gameSave.Rewind(step)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	saveGame(gameSave, gameCtx)
+	liveGames.Publish(gameCtx, played)
+	log.Printf("Game: %s rewound to move %d", gameCtx, step)
+	http.Redirect(w, req, "/game/"+gameCtx, http.StatusSeeOther)
+}
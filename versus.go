@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/deadly990/gominesweeper/controllers"
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/live"
+	"github.com/deadly990/gominesweeper/storage"
+	"github.com/deadly990/gominesweeper/versus"
+	"github.com/deadly990/gominesweeper/view"
+	"github.com/go-chi/chi/v5"
+)
+
+var liveMatches = live.NewHub(savedMatches{})
+
+// savedMatches plays flag battles stored on disk for the live hub.
+type savedMatches struct{}
+
+func (savedMatches) Load(name string) (*game.Game, error) {
+	matchSave, err := storage.LoadMatch(name)
+	if err != nil {
+		return nil, err
+	}
+	return matchSave.ToMatch().Game, nil
+}
+
+func (savedMatches) Play(name string, player string, command controllers.ClickCommand) (*game.Game, error) {
+	match, err := playMatch(name, player, game.Coordinate{X: command.XCoordinate, Y: command.YCoordinate})
+	if err != nil {
+		return nil, err
+	}
+	return match.Game, nil
+}
+
+// Starts a flag battle on a random board with the player creating it in the first seat.
+func createMatchHandler(w http.ResponseWriter, req *http.Request) {
+	id := generateName(rand.Int63())
+	matchSave := &storage.MatchSave{
+		Seed:      rand.Int63(),
+		Width:     versus.Width,
+		Height:    versus.Height,
+		MineCount: versus.Mines,
+		Players:   []string{playerName(w, req)},
+	}
+	if err := matchSave.Save(id); err != nil {
+		log.Println("Save:", err)
+		http.Error(w, "match could not be created", 500)
+		return
+	}
+	http.Redirect(w, req, "/versus/"+id, http.StatusSeeOther)
+}
+
+func matchHandler(w http.ResponseWriter, req *http.Request) {
+	matchCtx := req.Context().Value(MatchIDString).(string)
+	matchSave, err := storage.LoadMatch(matchCtx)
+	if err != nil {
+		http.Error(w, "match not found", 404)
+		return
+	}
+	renderMatch(w, req, matchSave.ToMatch(), matchCtx)
+}
+
+// Seats the requesting player as the opponent in a flag battle and starts the match.
+func joinMatchHandler(w http.ResponseWriter, req *http.Request) {
+	matchCtx := req.Context().Value(MatchIDString).(string)
+	unlock := lockGame(matchCtx)
+	defer unlock()
+	matchSave, err := storage.LoadMatch(matchCtx)
+	if err != nil {
+		http.Error(w, "match not found", 404)
+		return
+	}
+	match := matchSave.ToMatch()
+	if err := match.Join(playerName(w, req)); err != nil {
+		http.Error(w, err.Error(), 409)
+		return
+	}
+	if matchSave.Started == 0 && len(match.Players) == versus.Seats {
+		matchSave.Started = time.Now().UnixMilli()
+	}
+	matchSave.Record(*match)
+	if err := matchSave.Save(matchCtx); err != nil {
+		log.Println("Save:", err)
+	}
+	liveMatches.Touch(matchCtx, match.Game)
+	http.Redirect(w, req, "/versus/"+matchCtx, http.StatusSeeOther)
+}
+
+func matchMoveHandler(w http.ResponseWriter, req *http.Request) {
+	matchCtx := req.Context().Value(MatchIDString).(string)
+	clickCtx := req.Context().Value(ClickLocationString).(string)
+	coord, err := parseClickLocation(clickCtx)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	match, err := playMatch(matchCtx, playerName(w, req), coord)
+	switch {
+	case errors.Is(err, versus.ErrNotPlayer):
+		http.Error(w, err.Error(), 403)
+		return
+	case errors.Is(err, versus.ErrOutOfRange):
+		http.Error(w, err.Error(), 400)
+		return
+	case errors.Is(err, versus.ErrWaiting), errors.Is(err, versus.ErrNotYourTurn), errors.Is(err, versus.ErrOver), errors.Is(err, versus.ErrTaken):
+		http.Error(w, err.Error(), 409)
+		return
+	case err != nil:
+		http.Error(w, "match not found", 404)
+		return
+	}
+	renderMatch(w, req, match, matchCtx)
+	log.Printf("Match: %s pick: %s", matchCtx, clickCtx)
+}
+
+// Upgrades to a WebSocket that streams changes to a flag battle and accepts picks from its players.
+func matchLiveHandler(w http.ResponseWriter, req *http.Request) {
+	matchCtx := req.Context().Value(MatchIDString).(string)
+	matchSave, err := storage.LoadMatch(matchCtx)
+	if err != nil {
+		http.Error(w, "match not found", 404)
+		return
+	}
+	player := playerName(w, req)
+	server := liveMatches.Server(matchCtx, player, matchSave.ToMatch().Seat(player) < 0)
+	server.ServeHTTP(w, req)
+}
+
+// Picks a tile for a player in a saved flag battle, saves the result and publishes it to anyone watching.
+func playMatch(name string, player string, coord game.Coordinate) (*versus.Match, error) {
+	defer lockGame(name)()
+
+	matchSave, err := storage.LoadMatch(name)
+	if err != nil {
+		return nil, err
+	}
+	match := matchSave.ToMatch()
+	elapsed := time.Duration(0)
+	if matchSave.Started != 0 {
+		elapsed = time.Since(time.UnixMilli(matchSave.Started))
+	}
+	if _, err := match.Play(player, versus.Move{Coordinate: coord, Elapsed: elapsed}); err != nil {
+		return nil, err
+	}
+	matchSave.Record(*match)
+	if match.Over() {
+		matchSave.Finished = time.Now().UnixMilli()
+	}
+	if err := matchSave.Save(name); err != nil {
+		log.Println("Save:", err)
+	}
+	liveMatches.Publish(name, match.Game)
+	return match, nil
+}
+
+// Renders a flag battle, or only its board and scores when the page's script asks for a fragment.
+func renderMatch(w http.ResponseWriter, req *http.Request, match *versus.Match, name string) {
+	page := "versus.html"
+	w.Header().Add("Vary", FragmentHeader)
+	if isFragmentRequest(req) {
+		page = "versus_play.html"
+	}
+	executeTemplate(w, page, view.FromMatch(*match, name, playerName(w, req)))
+}
+
+func MatchCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), MatchIDString, chi.URLParam(req, string(MatchIDString)))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,129 @@
+// Package versus plays flag battles, where two players take turns on one board and score by finding mines.
+//
+// The player whose turn it is picks a hidden tile. A mine is claimed for them and they go again, any other
+// tile is revealed as usual and the turn passes to their opponent. The first player to claim more than half
+// of the mines wins.
+package versus
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+// Seats is the number of players in a match.
+const Seats = 2
+
+// The board every match is played on. An odd number of mines means a match can never be drawn.
+const (
+	Width  = 16
+	Height = 16
+	Mines  = 51
+)
+
+var ErrWaiting = errors.New("waiting for an opponent to join")
+var ErrFull = errors.New("match already has two players")
+var ErrNotPlayer = errors.New("player is not in this match")
+var ErrNotYourTurn = errors.New("it is not your turn")
+var ErrOver = errors.New("match is over")
+var ErrOutOfRange = errors.New("tile is not on the board")
+var ErrTaken = errors.New("tile has already been revealed or claimed")
+
+// Move is a tile picked by the player in a seat.
+type Move struct {
+	game.Coordinate
+	Seat int
+	// Elapsed is the time since the match started that the move was made.
+	Elapsed time.Duration
+	// Claimed is true if the tile was a mine.
+	Claimed bool
+}
+
+// Match is a flag battle. Claimed mines are flagged on the underlying Game and revealed tiles cleared on it.
+type Match struct {
+	Game    *game.Game
+	Players []string
+	// Owners holds one more than the seat that claimed each tile, zero for tiles nobody has claimed.
+	Owners [][]int
+	Scores [Seats]int
+	// Turn is the seat of the player to move next.
+	Turn  int
+	Moves []Move
+}
+
+// Returns a match on a board with the player who created it in the first seat.
+func NewMatch(board generation.Board, host string) *Match {
+	owners := make([][]int, len(board.Field))
+	for y, row := range board.Field {
+		owners[y] = make([]int, len(row))
+	}
+	return &Match{Game: game.NewGame(board), Players: []string{host}, Owners: owners}
+}
+
+// Seats a second player. Joining a match already played in is a no-op.
+func (match *Match) Join(player string) error {
+	if slices.Contains(match.Players, player) {
+		return nil
+	}
+	if len(match.Players) == Seats {
+		return ErrFull
+	}
+	match.Players = append(match.Players, player)
+	return nil
+}
+
+// Returns the seat of a player, or -1 if they are not in the match.
+func (match *Match) Seat(player string) int {
+	return slices.Index(match.Players, player)
+}
+
+// Picks the tile of a move for a player, claiming it if it is a mine and revealing it otherwise.
+// Returns the move made, or an error describing why the player may not pick the tile.
+func (match *Match) Play(player string, move Move) (Move, error) {
+	coord := move.Coordinate
+	seat := match.Seat(player)
+	switch {
+	case seat < 0:
+		return Move{}, ErrNotPlayer
+	case len(match.Players) < Seats:
+		return Move{}, ErrWaiting
+	case match.Over():
+		return Move{}, ErrOver
+	case seat != match.Turn:
+		return Move{}, ErrNotYourTurn
+	case !match.Game.Board.IsInRange(coord.Y, coord.X):
+		return Move{}, ErrOutOfRange
+	case match.Game.Revealed[coord.Y][coord.X] >= 0 || match.Owners[coord.Y][coord.X] != 0:
+		return Move{}, ErrTaken
+	}
+	move.Seat = seat
+	move.Claimed = match.Game.Board.Field[coord.Y][coord.X] == -9
+	if move.Claimed {
+		match.Game.ToggleFlag(coord)
+		match.Owners[coord.Y][coord.X] = seat + 1
+		match.Scores[seat]++
+	} else {
+		match.Game.Reveal(coord)
+		match.Turn = (seat + 1) % Seats
+	}
+	match.Moves = append(match.Moves, move)
+	return move, nil
+}
+
+// Returns true once a player has claimed a majority of the mines or every mine has been claimed.
+func (match *Match) Over() bool {
+	return match.Winner() >= 0 || match.Scores[0]+match.Scores[1] == match.Game.Board.Mines
+}
+
+// Returns the seat of the player who has claimed more than half of the mines, or -1 if nobody has yet.
+func (match *Match) Winner() int {
+	for seat, score := range match.Scores {
+		if score*2 > match.Game.Board.Mines {
+			return seat
+		}
+	}
+	return -1
+}
@@ -0,0 +1,80 @@
+package versus
+
+import (
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/generation"
+)
+
+func newTestMatch(test *testing.T) *Match {
+	board, err := generation.FromLayout([]string{
+		"*..",
+		"...",
+		"*.*",
+	})
+	if err != nil {
+		log.Printf("Error creating board: %s", err)
+		test.FailNow()
+	}
+	return NewMatch(*board, "alice")
+}
+
+func TestTurns(test *testing.T) {
+	match := newTestMatch(test)
+	if _, err := match.Play("alice", Move{Coordinate: game.Coordinate{X: 1, Y: 1}}); !errors.Is(err, ErrWaiting) {
+		log.Printf("Expected moves to wait for an opponent. Actual: %v", err)
+		test.Fail()
+	}
+	match.Join("bob")
+	if err := match.Join("carol"); !errors.Is(err, ErrFull) {
+		log.Printf("Expected a third player to be turned away. Actual: %v", err)
+		test.Fail()
+	}
+	if _, err := match.Play("bob", Move{Coordinate: game.Coordinate{X: 1, Y: 1}}); !errors.Is(err, ErrNotYourTurn) {
+		log.Printf("Expected the host to move first. Actual: %v", err)
+		test.Fail()
+	}
+
+	move, err := match.Play("alice", Move{Coordinate: game.Coordinate{X: 0, Y: 0}})
+	if err != nil || !move.Claimed || match.Turn != 0 || match.Scores[0] != 1 || !match.Game.Flagged(game.Coordinate{X: 0, Y: 0}) {
+		log.Printf("Expected alice to claim the mine and move again. Actual: %+v %v", match, err)
+		test.Fail()
+	}
+	if _, err := match.Play("alice", Move{Coordinate: game.Coordinate{X: 0, Y: 0}}); !errors.Is(err, ErrTaken) {
+		log.Printf("Expected a claimed mine to be taken. Actual: %v", err)
+		test.Fail()
+	}
+
+	move, err = match.Play("alice", Move{Coordinate: game.Coordinate{X: 1, Y: 1}})
+	if err != nil || move.Claimed || match.Turn != 1 || match.Game.Revealed[1][1] != 3 {
+		log.Printf("Expected alice to reveal a safe tile and pass the turn. Actual: %+v %v", match, err)
+		test.Fail()
+	}
+	if len(match.Moves) != 2 {
+		log.Printf("Expected two moves to be recorded. Actual: %+v", match.Moves)
+		test.Fail()
+	}
+}
+
+func TestMajorityWins(test *testing.T) {
+	match := newTestMatch(test)
+	match.Join("bob")
+	match.Play("alice", Move{Coordinate: game.Coordinate{X: 1, Y: 0}})
+	match.Play("bob", Move{Coordinate: game.Coordinate{X: 0, Y: 0}})
+	if match.Over() {
+		log.Printf("Expected the match to continue with one of three mines claimed.")
+		test.Fail()
+	}
+	match.Play("bob", Move{Coordinate: game.Coordinate{X: 2, Y: 2}})
+	if !match.Over() || match.Winner() != 1 {
+		log.Printf("Expected bob to win with two of three mines. Actual: %+v", match.Scores)
+		test.Fail()
+	}
+	if _, err := match.Play("bob", Move{Coordinate: game.Coordinate{X: 0, Y: 2}}); !errors.Is(err, ErrOver) {
+		log.Printf("Expected no moves after the match is over. Actual: %v", err)
+		test.Fail()
+	}
+}
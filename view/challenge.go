@@ -0,0 +1,33 @@
+package view
+
+import "github.com/deadly990/gominesweeper/storage"
+
+// ChallengeData describes the results of a daily challenge.
+type ChallengeData struct {
+	Date        string
+	Played      bool
+	Won         bool
+	Time        string
+	Game        string
+	Leaderboard []LeaderboardEntry
+}
+
+// Returns the results of a daily challenge from the viewing player's attempt, if any, and the day's leaderboard.
+func FromChallenge(date string, attempt *storage.Attempt, leaderboard []storage.Attempt, player string) ChallengeData {
+	data := ChallengeData{Date: date, Leaderboard: []LeaderboardEntry{}}
+	if attempt != nil {
+		data.Played = true
+		data.Won = attempt.Won
+		data.Time = FormatDuration(attempt.Duration())
+		data.Game = attempt.Game
+	}
+	for index, entry := range leaderboard {
+		data.Leaderboard = append(data.Leaderboard, LeaderboardEntry{
+			Rank:   index + 1,
+			Player: ShortName(entry.Player),
+			Time:   FormatDuration(entry.Duration()),
+			Mine:   entry.Player == player,
+		})
+	}
+	return data
+}
@@ -0,0 +1,48 @@
+package view
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// hexRadius is the distance from the centre of a drawn hexagon to each of its corners.
+const hexRadius = 14.0
+
+// HexTile is a tile of a hex board, drawn as a pointy-topped hexagon centred on X, Y.
+type HexTile struct {
+	Tile
+	X      float64
+	Y      float64
+	Points string
+}
+
+// Lays out the tiles of a hex board for drawing as SVG, returning them with the width and height of the drawing.
+// Odd rows are shifted half a tile right, matching the neighbors of generation.Hex.
+func hexLayout(squares [][]Tile) ([]HexTile, float64, float64) {
+	width := math.Sqrt(3) * hexRadius
+	rowHeight := 1.5 * hexRadius
+	hexes := []HexTile{}
+	columns := 0
+	for y, row := range squares {
+		columns = max(columns, len(row))
+		for x, tile := range row {
+			centreX := width/2 + float64(x)*width + float64(y&1)*width/2
+			centreY := hexRadius + float64(y)*rowHeight
+			hexes = append(hexes, HexTile{Tile: tile, X: centreX, Y: centreY, Points: hexPoints(centreX, centreY)})
+		}
+	}
+	totalWidth := float64(columns)*width + width/2
+	totalHeight := float64(len(squares)-1)*rowHeight + 2*hexRadius
+	return hexes, totalWidth, totalHeight
+}
+
+// Returns the corners of a pointy-topped hexagon as an SVG points list.
+func hexPoints(centreX float64, centreY float64) string {
+	corners := make([]string, 6)
+	for corner := range corners {
+		angle := math.Pi / 180 * float64(60*corner-30)
+		corners[corner] = fmt.Sprintf("%.1f,%.1f", centreX+hexRadius*math.Cos(angle), centreY+hexRadius*math.Sin(angle))
+	}
+	return strings.Join(corners, " ")
+}
@@ -0,0 +1,93 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+// StatsRow is a player's statistics for one difficulty.
+type StatsRow struct {
+	Difficulty    string
+	Played        int
+	Won           int
+	WinRate       string
+	Best          string
+	Average3BVPS  string
+	CurrentStreak int
+	LongestStreak int
+}
+
+// GameRow is a past game in a player's history.
+type GameRow struct {
+	URL        string
+	Difficulty string
+	Started    string
+	Result     string
+	Time       string
+	ThreeBVPS  string
+}
+
+// HistoryData describes a player's history page.
+type HistoryData struct {
+	Stats []StatsRow
+	Games []GameRow
+	Page  int
+	// Previous and Next are links to the neighboring pages, empty if there is none.
+	Previous string
+	Next     string
+}
+
+// HistoryJSON is the API representation of a player's history.
+type HistoryJSON struct {
+	Player string                    `json:"player"`
+	Stats  []storage.DifficultyStats `json:"stats"`
+	Games  []storage.GameRecord      `json:"games"`
+}
+
+// Returns the history page of a player's statistics and a page of their games.
+func FromHistory(stats []storage.DifficultyStats, records []storage.GameRecord, page int, more bool) HistoryData {
+	data := HistoryData{Stats: []StatsRow{}, Games: []GameRow{}, Page: page}
+	for _, current := range stats {
+		row := StatsRow{
+			Difficulty:    current.Difficulty,
+			Played:        current.Played,
+			Won:           current.Won,
+			WinRate:       percent(current.WinRate),
+			Best:          "-",
+			Average3BVPS:  "-",
+			CurrentStreak: current.CurrentStreak,
+			LongestStreak: current.LongestStreak,
+		}
+		if current.Won > 0 {
+			row.Best = FormatDuration(time.Duration(current.BestMs) * time.Millisecond)
+			row.Average3BVPS = fmt.Sprintf("%.2f", current.AverageThreeBVPS)
+		}
+		data.Stats = append(data.Stats, row)
+	}
+	for _, record := range records {
+		row := GameRow{
+			URL:        "/game/" + record.Name,
+			Difficulty: record.Difficulty,
+			Started:    time.UnixMilli(record.Started).UTC().Format("2006-01-02 15:04"),
+			Result:     "In progress",
+		}
+		if record.Finished != 0 {
+			row.Result = "Lost"
+			if record.Won {
+				row.Result = "Won"
+			}
+			row.Time = FormatDuration(time.Duration(record.DurationMs) * time.Millisecond)
+			row.ThreeBVPS = fmt.Sprintf("%.2f", record.ThreeBVPS)
+		}
+		data.Games = append(data.Games, row)
+	}
+	if page > 0 {
+		data.Previous = fmt.Sprintf("/history?page=%d", page-1)
+	}
+	if more {
+		data.Next = fmt.Sprintf("/history?page=%d", page+1)
+	}
+	return data
+}
@@ -0,0 +1,30 @@
+package view
+
+// LayerView is one layer of a three dimensional board, drawn as a board of its own.
+type LayerView struct {
+	// Number counts the layers from 1 at the top.
+	Number int
+	Board  MineView
+	// Previous and Next are the numbers of the layers above and below, zero at the top and bottom.
+	Previous int
+	Next     int
+}
+
+// Returns the layers of a three dimensional board from the top down, each sharing the settings of the whole
+// board. Flat boards have none.
+func (mineView MineView) LayerViews() []LayerView {
+	if mineView.Layers < 2 {
+		return nil
+	}
+	rows := len(mineView.Squares) / mineView.Layers
+	layers := make([]LayerView, mineView.Layers)
+	for index := range layers {
+		board := mineView
+		board.Squares, board.Layers = mineView.Squares[index*rows:(index+1)*rows], 0
+		layers[index] = LayerView{Number: index + 1, Board: board, Previous: index}
+		if index+1 < mineView.Layers {
+			layers[index].Next = index + 2
+		}
+	}
+	return layers
+}
@@ -0,0 +1,125 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+// LeaderboardEntry is a ranked result on a leaderboard.
+type LeaderboardEntry struct {
+	Rank   int
+	Player string
+	Time   string
+	Mine   bool
+	// ThreeBVPS and Game are only set on the difficulty leaderboards, and LivesUsed on those played with lives.
+	ThreeBVPS string
+	Game      string
+	LivesUsed string
+}
+
+// LeaderboardData describes the leaderboard of a ranked difficulty.
+type LeaderboardData struct {
+	Difficulty   string
+	Ranking      storage.Ranking
+	Difficulties []string
+	// Lives is set on the leaderboards of games played with lives, and LivesDifficulties names those leaderboards.
+	Lives             bool
+	LivesDifficulties []string
+	Top               []LeaderboardEntry
+	// Around is the player's own result and its neighbors, empty if the player has no result or is in Top.
+	Around []LeaderboardEntry
+}
+
+// LeaderboardJSON is the API representation of a leaderboard.
+type LeaderboardJSON struct {
+	Difficulty string          `json:"difficulty"`
+	Ranking    storage.Ranking `json:"ranking"`
+	Top        []ResultJSON    `json:"top"`
+	Around     []ResultJSON    `json:"around"`
+}
+
+// ResultJSON is the API representation of a ranked result. Players are known by their ShortName, since the full
+// name of a guest is what identifies them to the server.
+type ResultJSON struct {
+	Rank       int     `json:"rank"`
+	Player     string  `json:"player"`
+	Mine       bool    `json:"mine"`
+	Game       string  `json:"game"`
+	DurationMs int64   `json:"durationMs"`
+	ThreeBVPS  float64 `json:"3bvPerSecond"`
+	Finished   int64   `json:"finished"`
+	LivesUsed  int     `json:"livesUsed,omitempty"`
+}
+
+// Returns the API representation of a leaderboard, marking the player's own results.
+func ToLeaderboardJSON(difficulty string, ranking storage.Ranking, top []storage.Result, around []storage.Result, player string) LeaderboardJSON {
+	return LeaderboardJSON{
+		Difficulty: difficulty,
+		Ranking:    ranking,
+		Top:        toResultsJSON(top, player),
+		Around:     toResultsJSON(around, player),
+	}
+}
+
+func toResultsJSON(results []storage.Result, player string) []ResultJSON {
+	resultsJSON := []ResultJSON{}
+	for _, result := range results {
+		resultsJSON = append(resultsJSON, ResultJSON{
+			Rank:       result.Rank,
+			Player:     ShortName(result.Player),
+			Mine:       result.Player == player,
+			Game:       result.Name,
+			DurationMs: result.DurationMs,
+			ThreeBVPS:  result.ThreeBVPS,
+			Finished:   result.Finished,
+			LivesUsed:  result.LivesUsed,
+		})
+	}
+	return resultsJSON
+}
+
+// Returns the leaderboard page of a difficulty, highlighting the player's results.
+func FromLeaderboard(difficulty string, ranking storage.Ranking, top []storage.Result, around []storage.Result, player string) LeaderboardData {
+	data := LeaderboardData{
+		Difficulty:   difficulty,
+		Ranking:      ranking,
+		Difficulties: generation.PresetNames,
+		Around:       []LeaderboardEntry{},
+		Lives:        strings.HasPrefix(difficulty, storage.LivesPrefix),
+	}
+	data.Top = toLeaderboardEntries(top, player, data.Lives)
+	for _, name := range generation.PresetNames {
+		data.LivesDifficulties = append(data.LivesDifficulties, storage.LivesLeaderboard(name))
+	}
+	for _, entry := range data.Top {
+		if entry.Mine {
+			return data
+		}
+	}
+	data.Around = toLeaderboardEntries(around, player, data.Lives)
+	return data
+}
+
+func toLeaderboardEntries(results []storage.Result, player string, lives bool) []LeaderboardEntry {
+	entries := []LeaderboardEntry{}
+	for _, result := range results {
+		livesUsed := ""
+		if lives {
+			livesUsed = fmt.Sprint(result.LivesUsed)
+		}
+		entries = append(entries, LeaderboardEntry{
+			Rank:      result.Rank,
+			Player:    ShortName(result.Player),
+			Time:      FormatDuration(time.Duration(result.DurationMs) * time.Millisecond),
+			Mine:      result.Player == player,
+			ThreeBVPS: fmt.Sprintf("%.2f", result.ThreeBVPS),
+			Game:      "/game/" + result.Name,
+			LivesUsed: livesUsed,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,201 @@
+package view
+
+import (
+	"time"
+
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/race"
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+// LobbyEntry is a race waiting for players.
+type LobbyEntry struct {
+	URL        string
+	Difficulty string
+	Host       string
+	Players    int
+}
+
+// RacesData lists the open race lobbies.
+type RacesData struct {
+	Lobbies      []LobbyEntry
+	Difficulties []string
+}
+
+// RacerView is a player's standing in a race.
+type RacerView struct {
+	Rank   int
+	Player string
+	// Status is one of waiting, ready, racing, won, out or left.
+	Status  string
+	Cleared string
+	Time    string
+	Mine    bool
+}
+
+// RaceData describes a race from its lobby through to its results.
+type RaceData struct {
+	ID         string
+	Difficulty string
+	Status     race.Status
+	// Start is the Unix time in milliseconds the race starts, for the countdown.
+	Start   int64
+	Players []RacerView
+	Joined  bool
+	Ready   bool
+	// Game is the name of the player's own game once the race has started.
+	Game string
+}
+
+// Returns the list of open lobbies.
+func FromLobbies(lobbies []race.State) RacesData {
+	data := RacesData{Lobbies: []LobbyEntry{}, Difficulties: generation.PresetNames}
+	for _, lobby := range lobbies {
+		data.Lobbies = append(data.Lobbies, LobbyEntry{
+			URL:        "/race/" + lobby.ID,
+			Difficulty: lobby.Difficulty,
+			Host:       ShortName(lobby.Players[0].Name),
+			Players:    len(lobby.Players),
+		})
+	}
+	return data
+}
+
+// Returns the view of a race in memory as seen by a player.
+func FromRace(state race.State, player string) RaceData {
+	data := RaceData{ID: state.ID, Difficulty: state.Difficulty, Status: state.Status, Start: state.Start, Players: []RacerView{}}
+	for index, racer := range state.Players {
+		racerView := RacerView{
+			Player:  ShortName(racer.Name),
+			Status:  racerStatus(racer, state.Status),
+			Cleared: percent(racer.Cleared),
+			Mine:    racer.Name == player,
+		}
+		if state.Status == race.Finished {
+			racerView.Rank = index + 1
+		}
+		if racer.Done() {
+			racerView.Time = FormatDuration(time.Duration(racer.Finished-state.Start) * time.Millisecond)
+		}
+		if racerView.Mine {
+			data.Joined = true
+			data.Ready = racer.Ready
+			data.Game = racer.Game
+		}
+		data.Players = append(data.Players, racerView)
+	}
+	return data
+}
+
+// Returns the view of a race that is only known from its recorded results.
+func FromRaceResults(id string, results []storage.RaceResult, player string) RaceData {
+	data := RaceData{ID: id, Status: race.Finished, Players: []RacerView{}}
+	for _, result := range results {
+		status := "out"
+		if result.Won {
+			status = "won"
+		}
+		if result.Player == player {
+			data.Joined = true
+			data.Game = result.Game
+		}
+		data.Players = append(data.Players, RacerView{
+			Rank:    result.Rank,
+			Player:  ShortName(result.Player),
+			Status:  status,
+			Cleared: percent(result.Cleared),
+			Time:    FormatDuration(time.Duration(result.DurationMs) * time.Millisecond),
+			Mine:    result.Player == player,
+		})
+	}
+	return data
+}
+
+// RaceJSON is the API representation of a race in memory. Racers are known by their ShortName, since the full name
+// of a guest is what identifies them to the server.
+type RaceJSON struct {
+	ID         string      `json:"id"`
+	Difficulty string      `json:"difficulty"`
+	Status     race.Status `json:"status"`
+	Start      int64       `json:"start,omitempty"`
+	Players    []RacerJSON `json:"players"`
+}
+
+// RacerJSON is the API representation of a racer and their progress.
+type RacerJSON struct {
+	Name     string  `json:"name"`
+	Mine     bool    `json:"mine"`
+	Ready    bool    `json:"ready"`
+	Game     string  `json:"game,omitempty"`
+	Cleared  float64 `json:"cleared"`
+	Alive    bool    `json:"alive"`
+	Won      bool    `json:"won"`
+	Left     bool    `json:"left,omitempty"`
+	Finished int64   `json:"finished,omitempty"`
+}
+
+// RaceResultJSON is the API representation of a recorded race result, with the racer known by their ShortName.
+type RaceResultJSON struct {
+	Race       string  `json:"race"`
+	Player     string  `json:"player"`
+	Mine       bool    `json:"mine"`
+	Rank       int     `json:"rank"`
+	Game       string  `json:"game"`
+	Won        bool    `json:"won"`
+	DurationMs int64   `json:"durationMs"`
+	Cleared    float64 `json:"cleared"`
+}
+
+// Returns the API representation of a race in memory, marking the player's own progress.
+func ToRaceJSON(state race.State, player string) RaceJSON {
+	raceJSON := RaceJSON{ID: state.ID, Difficulty: state.Difficulty, Status: state.Status, Start: state.Start, Players: []RacerJSON{}}
+	for _, racer := range state.Players {
+		raceJSON.Players = append(raceJSON.Players, RacerJSON{
+			Name:     ShortName(racer.Name),
+			Mine:     racer.Name == player,
+			Ready:    racer.Ready,
+			Game:     racer.Game,
+			Cleared:  racer.Cleared,
+			Alive:    racer.Alive,
+			Won:      racer.Won,
+			Left:     racer.Left,
+			Finished: racer.Finished,
+		})
+	}
+	return raceJSON
+}
+
+// Returns the API representation of the recorded results of a race, marking the player's own.
+func ToRaceResultsJSON(results []storage.RaceResult, player string) []RaceResultJSON {
+	resultsJSON := []RaceResultJSON{}
+	for _, result := range results {
+		resultsJSON = append(resultsJSON, RaceResultJSON{
+			Race:       result.Race,
+			Player:     ShortName(result.Player),
+			Mine:       result.Player == player,
+			Rank:       result.Rank,
+			Game:       result.Game,
+			Won:        result.Won,
+			DurationMs: result.DurationMs,
+			Cleared:    result.Cleared,
+		})
+	}
+	return resultsJSON
+}
+
+func racerStatus(racer race.Player, status race.Status) string {
+	switch {
+	case status == race.Lobby && racer.Ready:
+		return "ready"
+	case status == race.Lobby:
+		return "waiting"
+	case racer.Won:
+		return "won"
+	case racer.Left:
+		return "left"
+	case !racer.Alive:
+		return "out"
+	default:
+		return "racing"
+	}
+}
@@ -0,0 +1,57 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deadly990/gominesweeper/game"
+)
+
+// ReplaySpeeds are the playback speeds offered by the replay viewer.
+var ReplaySpeeds = []float64{0.5, 1, 2, 4}
+
+// ReplayData describes one step of a replay of a finished game.
+type ReplayData struct {
+	Mine MineView
+	// Step is the number of moves played so far, out of Total.
+	Step  int
+	Total int
+	// Move describes the last move played, empty before the first.
+	Move    string
+	Playing bool
+	Speed   float64
+	Speeds  []float64
+	// Refresh is the delay in seconds before the next step while playing, empty when paused or finished.
+	Refresh string
+}
+
+// Returns the replay of a game after step of all its moves, highlighting the tile of the last move played.
+func FromReplay(replay game.Game, moves []game.Move, name string, step int, playing bool, speed float64) ReplayData {
+	mineView := FromGame(replay, name)
+	mineView.ReadOnly = true
+	data := ReplayData{
+		Mine:    mineView,
+		Step:    step,
+		Total:   len(moves),
+		Playing: playing && step < len(moves),
+		Speed:   speed,
+		Speeds:  ReplaySpeeds,
+	}
+	var previous time.Duration
+	if step > 0 {
+		move := moves[step-1]
+		mineView.Squares[move.Y][move.X].Highlighted = true
+		if mineView.Hexes != nil {
+			mineView.Hexes[move.Y*len(mineView.Squares[0])+move.X].Highlighted = true
+		}
+		data.Move = fmt.Sprintf("%s %d,%d at %s", move.Action, move.X, move.Y, FormatDuration(move.Elapsed))
+		previous = move.Elapsed
+	}
+	if data.Playing {
+		// Moves are replayed at the pace they were played, scaled by the speed.
+		delay := time.Duration(float64(moves[step].Elapsed-previous) / speed)
+		delay = max(100*time.Millisecond, min(delay, 5*time.Second))
+		data.Refresh = fmt.Sprintf("%.2f", delay.Seconds())
+	}
+	return data
+}
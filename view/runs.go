@@ -0,0 +1,94 @@
+package view
+
+import (
+	"time"
+
+	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/run"
+	"github.com/deadly990/gominesweeper/storage"
+)
+
+// RunView describes a time attack or endless run.
+type RunView struct {
+	Name       string
+	Mode       string
+	Title      string
+	Difficulty string
+	Score      int
+	// Stage counts the boards of the run from 1.
+	Stage int
+	// Remaining is the time left in a time attack run, empty once it is over and for endless runs.
+	Remaining string
+	Over      bool
+}
+
+// Returns the view of a run at a moment.
+func FromRun(current storage.Run, now time.Time) *RunView {
+	runView := &RunView{
+		Name:       current.Name,
+		Mode:       current.Mode,
+		Title:      run.Mode(current.Mode).Title(),
+		Difficulty: current.Difficulty,
+		Score:      current.Score,
+		Stage:      current.Stage + 1,
+		Over:       current.Over(now),
+	}
+	if remaining := current.Remaining(now); remaining > 0 {
+		runView.Remaining = FormatDuration(remaining)
+	}
+	return runView
+}
+
+// ModeOption is a run mode that can be chosen.
+type ModeOption struct {
+	Mode  string
+	Title string
+}
+
+// RunEntry is a ranked run on a run leaderboard.
+type RunEntry struct {
+	Rank   int
+	Player string
+	Score  int
+	// Time is how long the run took to reach its score.
+	Time string
+	Mine bool
+	URL  string
+}
+
+// RunsData is the page runs are started from, with the leaderboard of a mode on a difficulty.
+type RunsData struct {
+	Mode         string
+	Title        string
+	Difficulty   string
+	Modes        []ModeOption
+	Difficulties []string
+	Top          []RunEntry
+	// Run is the finished run being shown, nil when none is.
+	Run *RunView
+}
+
+// Returns the runs page for the leaderboard of a mode on a difficulty, highlighting the player's run.
+func FromRuns(mode run.Mode, difficulty string, top []storage.Run, player string) RunsData {
+	data := RunsData{
+		Mode:         string(mode),
+		Title:        mode.Title(),
+		Difficulty:   difficulty,
+		Difficulties: generation.PresetNames,
+		Top:          []RunEntry{},
+	}
+	for _, option := range run.Modes {
+		data.Modes = append(data.Modes, ModeOption{string(option), option.Title()})
+	}
+	for index, current := range top {
+		data.Top = append(data.Top, RunEntry{
+			Rank:   index + 1,
+			Player: ShortName(current.Player),
+			Score:  current.Score,
+			Time:   FormatDuration(time.Duration(current.ScoredMs) * time.Millisecond),
+			Mine:   current.Player == player,
+			URL:    "/run/" + current.Name,
+		})
+	}
+	return data
+}
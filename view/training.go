@@ -0,0 +1,71 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/deadly990/gominesweeper/game"
+	"github.com/deadly990/gominesweeper/solver"
+)
+
+// MistakeView explains a mistake made in a training game.
+type MistakeView struct {
+	// Move is the index of the mistaken reveal, which the game can be rewound to before.
+	Move        int
+	Explanation string
+}
+
+// Returns the explanations of the mistakes made in a training game, the latest first.
+func FromMistakes(played game.Game, mistakes []solver.Mistake) []MistakeView {
+	views := []MistakeView{}
+	for index := len(mistakes) - 1; index >= 0; index-- {
+		views = append(views, MistakeView{mistakes[index].Move, explain(played, mistakes[index])})
+	}
+	return views
+}
+
+// Returns a sentence or two explaining the deduction a mistake missed.
+func explain(played game.Game, mistake solver.Mistake) string {
+	tile := position(played, mistake.Coordinate)
+	if mistake.Mine {
+		switch mistake.Technique {
+		case solver.Single:
+			return fmt.Sprintf("The tile in %s was a mine: %s has exactly as many hidden tiles around it as mines "+
+				"left to find, so every one of them is a mine.", tile, number(played, mistake.Reasons[0]))
+		case solver.Subset:
+			return fmt.Sprintf("The tile in %s was a mine. Compare %s with %s: the tiles they share cannot hold "+
+				"all the mines of the first, so the rest are in the tiles only the first touches.", tile,
+				number(played, mistake.Reasons[0]), number(played, mistake.Reasons[1]))
+		default:
+			return fmt.Sprintf("The tile in %s was a mine. Every arrangement of mines that fits the numbers and "+
+				"the mine count puts one there.", tile)
+		}
+	}
+	guess := fmt.Sprintf("Revealing the tile in %s was a guess, but the tile in %s could be proven safe", tile,
+		position(played, *mistake.Safe))
+	switch mistake.Technique {
+	case solver.Single:
+		return fmt.Sprintf("%s: %s already touches all of its mines, so its other hidden tiles are safe.", guess,
+			number(played, mistake.Reasons[0]))
+	case solver.Subset:
+		return fmt.Sprintf("%s. Compare %s with %s: the tiles they share already hold every mine of the second, "+
+			"so the tiles only the second touches are safe.", guess, number(played, mistake.Reasons[0]),
+			number(played, mistake.Reasons[1]))
+	default:
+		return fmt.Sprintf("%s: no arrangement of mines that fits the numbers and the mine count puts one there.", guess)
+	}
+}
+
+// Returns where a tile is for players, counting rows and columns from 1 within its layer.
+func position(played game.Game, coord game.Coordinate) string {
+	grid := played.Board.Grid()
+	if grid.Layers > 1 {
+		layer, row := coord.Layer(grid)
+		return fmt.Sprintf("layer %d, row %d, column %d", layer+1, row+1, coord.X+1)
+	}
+	return fmt.Sprintf("row %d, column %d", coord.Y+1, coord.X+1)
+}
+
+// Returns a revealed number and where it is, such as "the 3 in row 2, column 5".
+func number(played game.Game, coord game.Coordinate) string {
+	return fmt.Sprintf("the %d in %s", played.Board.Field[coord.Y][coord.X], position(played, coord))
+}
@@ -0,0 +1,61 @@
+package view
+
+import "github.com/deadly990/gominesweeper/versus"
+
+// SeatView is a player's standing in a flag battle.
+type SeatView struct {
+	Player string
+	// Seat is one more than the player's seat, matching Tile.Owner.
+	Seat   int
+	Score  int
+	Turn   bool
+	Winner bool
+	Mine   bool
+}
+
+// VersusData describes a flag battle as seen by one of its players or a spectator.
+type VersusData struct {
+	Name    string
+	Squares [][]Tile
+	Seats   []SeatView
+	// Mines is the number of mines on the board and Needed the number a player must claim to win.
+	Mines    int
+	Needed   int
+	Waiting  bool
+	Over     bool
+	CanJoin  bool
+	YourTurn bool
+}
+
+// Returns the view of a flag battle as seen by a player.
+func FromMatch(match versus.Match, name string, player string) VersusData {
+	squares := convert(VisibleField(*match.Game), match.Game.Flags, name)
+	for y, row := range match.Owners {
+		for x, owner := range row {
+			squares[y][x].Owner = owner
+		}
+	}
+	data := VersusData{
+		Name:    name,
+		Squares: squares,
+		Seats:   []SeatView{},
+		Mines:   match.Game.Board.Mines,
+		Needed:  match.Game.Board.Mines/2 + 1,
+		Waiting: len(match.Players) < versus.Seats,
+		Over:    match.Over(),
+	}
+	for seat, seated := range match.Players {
+		data.Seats = append(data.Seats, SeatView{
+			Player: ShortName(seated),
+			Seat:   seat + 1,
+			Score:  match.Scores[seat],
+			Turn:   !data.Waiting && !data.Over && match.Turn == seat,
+			Winner: match.Winner() == seat,
+			Mine:   seated == player,
+		})
+	}
+	seat := match.Seat(player)
+	data.CanJoin = data.Waiting && seat < 0
+	data.YourTurn = !data.Waiting && !data.Over && seat == match.Turn
+	return data
+}
@@ -3,31 +3,123 @@ package view
 import (
 	"fmt"
 	"html/template"
+	"time"
 
+	"github.com/deadly990/gominesweeper/campaign"
 	"github.com/deadly990/gominesweeper/game"
 	"github.com/deadly990/gominesweeper/generation"
+	"github.com/deadly990/gominesweeper/solver"
+	"github.com/deadly990/gominesweeper/storage"
 )
 
 type Tile struct {
 	Value    int
 	Location string
 	GameID   string
+	Flagged  bool
+	// Highlighted marks the tile of the move being shown in a replay.
+	Highlighted bool
+	// Owner is one more than the seat of the player who claimed a mine in a flag battle, zero otherwise.
+	Owner int
+	// Flags is the number of flags on a tile of a board that stacks mines, zero otherwise.
+	Flags int
+	// Hit marks a mine that cost a life, which stays flagged.
+	Hit bool
 }
 
 func visible(square Tile) bool {
 	return square.Value >= 0
 }
 
+// Returns true if the tile is a revealed mine.
+func (tile Tile) Exploded() bool {
+	return tile.Value == game.Exploded
+}
+
 type MineView struct {
+	// Remaining is the number of mines less the number of flags placed.
 	Remaining int
 	Squares   [][]Tile
 	Name      string
+	Won       bool
+	Lost      bool
+	// ReadOnly hides the controls from players who do not own the game.
+	ReadOnly bool
+	// Topology names the shape of the board, empty for flat boards.
+	Topology string
+	// Rule explains what the numbers count on boards with a custom neighborhood, empty otherwise.
+	Rule string
+	// MinesPerTile is the most mines a tile can hold on boards that stack mines, zero otherwise.
+	MinesPerTile int
+	// Layers is the number of layers of a three dimensional board, zero for flat boards.
+	Layers int
+	// Lives is the number of lives the game started with and LivesLeft those remaining, zero for classic games.
+	Lives     int
+	LivesLeft int
+	// Hexes are the Squares laid out for drawing on hex boards, in a drawing HexWidth by HexHeight.
+	Hexes     []HexTile
+	HexWidth  float64
+	HexHeight float64
+}
+
+// LevelView describes the campaign level a game is being played on.
+type LevelView struct {
+	Key   string
+	Title string
+	Notes []string
+	// Next is the URL of the following level, empty if there is none.
+	Next string
 }
+
 type MainData struct {
 	Mine MineView
+	// Player is the username of a signed in player, empty for guests.
+	Player string
+	Level  *LevelView
+	// Daily is the date of the challenge being played, empty outside of challenge mode.
+	Daily string
+	// Rating and Stats are only shown once the game is over.
+	Rating *solver.Rating
+	Stats  game.Stats
+	// Spectators is the number of other players watching live.
+	Spectators   int
+	NoSpectators bool
+	// Race is the race the game is part of, empty for solo games.
+	Race string
+	// Run is the time attack or endless run the game is a stage of, nil otherwise.
+	Run *RunView
+	// Training is set for training games, which explain their Mistakes and count the times they were rewound.
+	Training bool
+	Mistakes []MistakeView
+	Retries  int
+	// Coop is set for cooperative games, which list what each of their Contributors has done.
+	// CanJoin is set when the player viewing the game may still join it.
+	Coop         bool
+	Contributors []ContributorRow
+	CanJoin      bool
+}
+
+// LevelEntry describes a level in the campaign listing.
+type LevelEntry struct {
+	URL       string
+	Title     string
+	Unlocked  bool
+	Completed bool
+}
+
+// PackView describes a campaign pack in the campaign listing.
+type PackView struct {
+	Title       string
+	Description string
+	Unlocked    bool
+	Levels      []LevelEntry
+}
+
+type CampaignData struct {
+	Packs []PackView
 }
 
-func convert(field [][]int, game string) [][]Tile {
+func convert(field [][]int, flags [][]bool, game string) [][]Tile {
 	squares := make([][]Tile, len(field))
 	for i := range squares {
 		squares[i] = make([]Tile, len(field[i]))
@@ -38,25 +130,244 @@ func convert(field [][]int, game string) [][]Tile {
 				Value:    field[i][j],
 				Location: fmt.Sprintf("%d_%d", i, j),
 				GameID:   game,
+				Flagged:  flags != nil && flags[i][j],
 			}
 		}
 	}
 	return squares
 }
+
 func FromBoard(board generation.Board, name string) MineView {
 	return MineView{
 		Remaining: board.Mines,
-		Squares:   convert(board.Field, name),
+		Squares:   convert(board.Field, nil, name),
+	}
+}
+
+// neighborhoodRules explain what the numbers count in each of the named neighborhoods.
+var neighborhoodRules = map[string]string{
+	"knight":     "Numbers count the mines a knight's move away.",
+	"orthogonal": "Numbers count only the mines directly above, below, left and right.",
+	"ring":       "Numbers count the mines in the ring of sixteen tiles two steps away.",
+}
+
+// Returns the explanation of a board's numbers, empty for the standard neighborhood.
+func neighborhoodRule(neighborhood generation.Neighborhood) string {
+	if neighborhood == nil {
+		return ""
+	}
+	if rule, ok := neighborhoodRules[neighborhood.Name()]; ok {
+		return rule
 	}
+	return fmt.Sprintf("Numbers count the mines at the offsets %s.", neighborhood)
 }
+
+// Returns the view of a game. Hidden tiles carry no value, so nothing under them reaches the page.
 func FromGame(game game.Game, name string) MineView {
-	return MineView{
-		Remaining: game.Board.Mines,
-		Squares:   convert(game.Revealed, name),
+	mineView := MineView{
+		Remaining: game.Board.Mines - game.FlagTotal(),
+		Squares:   convert(VisibleField(game), game.Flags, name),
+		Name:      name,
+		Won:       game.Won(),
+		Lost:      game.Lost(),
+		Topology:  string(game.Board.Topology),
+		Rule:      neighborhoodRule(game.Board.Neighborhood),
+		Layers:    game.Board.Layers,
+		Lives:     game.Lives,
+		LivesLeft: game.LivesLeft(),
+	}
+	for _, hit := range game.Hits {
+		mineView.Squares[hit.Y][hit.X].Hit = true
+	}
+	if game.FlagCounts != nil {
+		mineView.MinesPerTile = game.Board.MinesPerTile
+		for y, row := range game.FlagCounts {
+			for x, count := range row {
+				mineView.Squares[y][x].Flags = count
+			}
+		}
 	}
+	if game.Board.Topology == generation.Hex {
+		mineView.Hexes, mineView.HexWidth, mineView.HexHeight = hexLayout(mineView.Squares)
+	}
+	return mineView
+}
+
+// Returns the campaign listing with lock and completion state for a player's completed levels.
+func FromCampaign(packs []campaign.Pack, completed map[string]bool) CampaignData {
+	data := CampaignData{Packs: []PackView{}}
+	for _, pack := range packs {
+		packView := PackView{
+			Title:       pack.Title,
+			Description: pack.Description,
+			Unlocked:    pack.Unlocked(completed),
+			Levels:      []LevelEntry{},
+		}
+		for _, level := range pack.Levels {
+			packView.Levels = append(packView.Levels, LevelEntry{
+				URL:       LevelURL(pack.ID, level.ID),
+				Title:     level.Title,
+				Unlocked:  pack.LevelUnlocked(level.ID, completed),
+				Completed: completed[campaign.Key(pack.ID, level.ID)],
+			})
+		}
+		data.Packs = append(data.Packs, packView)
+	}
+	return data
+}
+
+// Returns the URL that starts a campaign level.
+func LevelURL(packID string, levelID string) string {
+	return fmt.Sprintf("/campaign/%s/%s", packID, levelID)
 }
+
+// Returns the LevelView of a campaign level.
+func FromLevel(pack campaign.Pack, level campaign.Level) *LevelView {
+	levelView := &LevelView{
+		Key:   campaign.Key(pack.ID, level.ID),
+		Title: level.Title,
+		Notes: level.Notes,
+	}
+	if next, ok := pack.Next(level.ID); ok {
+		levelView.Next = LevelURL(pack.ID, next.ID)
+	}
+	return levelView
+}
+
 func Generate() *template.Template {
 	return template.Must(template.New("").Funcs(template.FuncMap{
 		"IsVisible": visible,
+		"Duration":  FormatDuration,
+		"Percent":   percent,
+		"Add":       func(a int, b int) int { return a + b },
 	}).ParseGlob("./templates/*"))
 }
+
+// GameJSON is the API representation of a game. Tiles the player has not revealed are reported as -1
+// so the board is never exposed.
+type GameJSON struct {
+	Name   string  `json:"name"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Mines  int     `json:"mines"`
+	Field  [][]int `json:"field"`
+	Won    bool    `json:"won"`
+	Lost   bool    `json:"lost"`
+	// Topology is empty for flat boards, and Neighborhood for boards whose numbers count the surrounding eight tiles.
+	Topology     string                  `json:"topology,omitempty"`
+	Neighborhood generation.Neighborhood `json:"neighborhood,omitempty"`
+	// Layers is the number of layers of a three dimensional board, which are listed one after another in
+	// Field, each Height divided by Layers rows tall. It is empty for flat boards.
+	Layers int `json:"layers,omitempty"`
+	// Lives and LivesLeft are empty for classic games.
+	Lives     int `json:"lives,omitempty"`
+	LivesLeft int `json:"livesLeft,omitempty"`
+	// Rating is empty and Stats are zero until the game is over, since the 3BV and openings of a board give away
+	// tiles that are still hidden.
+	Rating *solver.Rating `json:"rating,omitempty"`
+	Stats  game.Stats     `json:"stats"`
+}
+
+// Returns the API representation of a game.
+func ToJSON(game game.Game, rating *solver.Rating, name string) GameJSON {
+	width, height := game.Board.BoardSize()
+	gameJSON := GameJSON{
+		Name:         name,
+		Width:        width,
+		Height:       height,
+		Mines:        game.Board.Mines,
+		Field:        VisibleField(game),
+		Won:          game.Won(),
+		Lost:         game.Lost(),
+		Topology:     string(game.Board.Topology),
+		Neighborhood: game.Board.Neighborhood,
+		Layers:       game.Board.Layers,
+		Lives:        game.Lives,
+		LivesLeft:    game.LivesLeft(),
+	}
+	if game.Over() {
+		gameJSON.Rating = rating
+		gameJSON.Stats = game.Stats()
+	}
+	return gameJSON
+}
+
+// Returns the field as the player sees it: revealed values, -2 for flagged tiles and -1 for other hidden tiles.
+// On boards that stack mines a tile with more than one flag is one lower for each flag past the first, so -3
+// has two flags.
+func VisibleField(game game.Game) [][]int {
+	field := solver.FromGame(game).Field
+	for y, row := range field {
+		for x := range row {
+			if game.Flags[y][x] {
+				field[y][x] = solver.Flagged
+				if game.FlagCounts != nil {
+					field[y][x] -= game.FlagCounts[y][x] - 1
+				}
+			}
+		}
+	}
+	return field
+}
+
+// Returns a ratio formatted as a percentage.
+func percent(ratio float64) string {
+	return fmt.Sprintf("%.0f%%", ratio*100)
+}
+
+// Returns a duration formatted to the hundredth of a second.
+func FormatDuration(duration time.Duration) string {
+	return fmt.Sprintf("%.2fs", duration.Seconds())
+}
+
+// Returns a shortened form of a generated player name for display.
+func ShortName(player string) string {
+	if len(player) > 8 {
+		return player[:8]
+	}
+	return player
+}
+
+// ContributorRow is one player's share of a cooperative game.
+type ContributorRow struct {
+	Player  string
+	Mine    bool
+	Clicks  int
+	Useful  int
+	Flags   int
+	Cleared int
+}
+
+// Returns a row for every contributor to a cooperative game, including those who have not moved yet.
+func FromContributions(contributors []string, contributions []game.Contribution, player string) []ContributorRow {
+	moved := map[string]game.Contribution{}
+	for _, contribution := range contributions {
+		moved[contribution.Player] = contribution
+	}
+	rows := []ContributorRow{}
+	for _, contributor := range contributors {
+		contribution := moved[contributor]
+		rows = append(rows, ContributorRow{
+			Player:  ShortName(contributor),
+			Mine:    contributor == player,
+			Clicks:  contribution.Clicks,
+			Useful:  contribution.Useful,
+			Flags:   contribution.Flags,
+			Cleared: contribution.Cleared,
+		})
+	}
+	return rows
+}
+
+// AccountData describes the account page.
+type AccountData struct {
+	// Username is the signed in player, empty for guests.
+	Username string
+	Error    string
+	// Bots are the bots the player has registered.
+	Bots []storage.Bot
+	// Token is the token of a bot just registered, shown only once.
+	Token string
+	// BotName is the name of the bot the token belongs to.
+	BotName string
+}